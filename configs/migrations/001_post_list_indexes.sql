@@ -0,0 +1,15 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客列表游标分页使用的索引.
+-- 用户博客列表按 username 过滤后按 (createdAt, id) 倒序分页，全站列表直接按 (createdAt, id) 倒序分页.
+-- (username, createdAt, id) 索引可以替代原来的 username 索引.
+
+USE `miniblog`;
+
+ALTER TABLE `post`
+  DROP INDEX `idx_username`,
+  ADD INDEX `idx_username_createdAt_id` (`username`, `createdAt`, `id`),
+  ADD INDEX `idx_createdAt_id` (`createdAt`, `id`);
@@ -0,0 +1,20 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加 user.postCount 列，并根据用户已有的博客回填计数.
+-- 这个迁移在 003_post_drafts.sql 添加 post.status 列之前执行，此时 post 表中只有已发布的博客.
+-- 之后 postCount 在创建、发布和删除博客时原子维护.
+-- user 表同时从 MyISAM 转换为 InnoDB，否则创建博客和递增 postCount 的事务不是原子的，
+-- SELECT ... FOR UPDATE 也不会锁住用户记录.
+
+USE `miniblog`;
+
+ALTER TABLE `user` ENGINE=InnoDB;
+
+ALTER TABLE `user` ADD COLUMN `postCount` int unsigned NOT NULL DEFAULT '0' AFTER `phone`;
+
+UPDATE `user` SET `postCount` = (
+  SELECT COUNT(*) FROM `post` WHERE `post`.`username` = `user`.`username`
+);
@@ -0,0 +1,19 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客的草稿和定时发布.
+-- 已有的博客都是已发布的，发布时间回填为创建时间.
+
+USE `miniblog`;
+
+ALTER TABLE `post`
+  ADD COLUMN `status` varchar(16) NOT NULL DEFAULT 'published' AFTER `content`,
+  ADD COLUMN `publishAt` timestamp NULL DEFAULT NULL AFTER `status`,
+  ADD COLUMN `publishedAt` timestamp NULL DEFAULT NULL AFTER `publishAt`,
+  ADD COLUMN `claimedBy` varchar(64) NOT NULL DEFAULT '' AFTER `publishedAt`,
+  ADD COLUMN `claimedAt` timestamp NULL DEFAULT NULL AFTER `claimedBy`,
+  ADD INDEX `idx_status_publishAt` (`status`, `publishAt`);
+
+UPDATE `post` SET `status` = 'published', `publishedAt` = `createdAt`, `updatedAt` = `updatedAt`;
@@ -0,0 +1,29 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客的修订历史.
+-- 已有的博客回填为第 1 个修订，并保存当前内容作为对应的修订记录，之后可以和新的修订对比或者恢复.
+
+USE `miniblog`;
+
+ALTER TABLE `post` ADD COLUMN `revision` int unsigned NOT NULL DEFAULT '0' AFTER `content`;
+
+CREATE TABLE `post_revision` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `revision` int unsigned NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `title` varchar(256) NOT NULL,
+  `content` longtext NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_revision` (`postID`,`revision`),
+  KEY `idx_postID_createdAt_id` (`postID`,`createdAt`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+UPDATE `post` SET `revision` = 1, `updatedAt` = `updatedAt` WHERE `revision` = 0;
+
+INSERT INTO `post_revision` (`postID`, `revision`, `username`, `title`, `content`, `createdAt`)
+SELECT `postID`, `revision`, `username`, `title`, `content`, `updatedAt` FROM `post`;
@@ -0,0 +1,11 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 修订历史的所有权改为在 biz 层校验，删除之前为博客所有者添加的修订历史授权策略.
+-- 在此之前创建的博客没有这些策略，所以不再依赖 casbin 判断修订历史的访问权限.
+
+USE `miniblog`;
+
+DELETE FROM `casbin_rule` WHERE `ptype` = 'p' AND `v1` LIKE '/v1/posts/%/revisions*';
@@ -0,0 +1,27 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客标签.
+
+USE `miniblog`;
+
+CREATE TABLE `tag` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `slug` varchar(128) NOT NULL,
+  `name` varchar(128) NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `slug` (`slug`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `post_tag` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `tagID` bigint unsigned NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_tagID` (`postID`,`tagID`),
+  KEY `idx_tagID` (`tagID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
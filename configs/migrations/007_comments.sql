@@ -0,0 +1,26 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客的评论.
+
+USE `miniblog`;
+
+CREATE TABLE `comment` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `commentID` varchar(256) NOT NULL,
+  `postID` varchar(256) NOT NULL,
+  `parentID` varchar(256) NOT NULL DEFAULT '',
+  `rootID` varchar(256) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `deleted` tinyint(1) NOT NULL DEFAULT '0',
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updatedAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `commentID` (`commentID`),
+  KEY `idx_postID_parentID_createdAt_id` (`postID`,`parentID`,`createdAt`,`id`),
+  KEY `idx_rootID` (`rootID`),
+  KEY `idx_parentID` (`parentID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 评论的所有权改为在 biz 层校验，删除之前为评论作者和博客所有者添加的评论授权策略.
+-- 级联删除评论和删除博客时这些策略不会被删除，会在 casbin_rule 表中不断堆积.
+
+USE `miniblog`;
+
+DELETE FROM `casbin_rule` WHERE `ptype` = 'p' AND `v1` LIKE '/v1/posts/%/comments/%';
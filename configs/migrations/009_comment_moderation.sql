@@ -0,0 +1,15 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加评论审核.
+-- 已有的评论都是公开的，status 使用默认值 approved；已有的博客默认不需要审核评论.
+
+USE `miniblog`;
+
+ALTER TABLE `comment`
+  ADD COLUMN `status` varchar(16) NOT NULL DEFAULT 'approved' AFTER `content`,
+  ADD INDEX `idx_status_postID_createdAt_id` (`status`, `postID`, `createdAt`, `id`);
+
+ALTER TABLE `post` ADD COLUMN `moderateComments` tinyint(1) NOT NULL DEFAULT '0' AFTER `claimedAt`;
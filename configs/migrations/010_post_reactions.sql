@@ -0,0 +1,27 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客的回应和按类型汇总的回应计数.
+
+USE `miniblog`;
+
+CREATE TABLE `post_reaction` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `kind` varchar(32) NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_username_kind` (`postID`,`username`,`kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `post_reaction_count` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `kind` varchar(32) NOT NULL,
+  `count` bigint NOT NULL DEFAULT '0',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_kind` (`postID`,`kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,19 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加用户之间的关注关系.
+
+USE `miniblog`;
+
+CREATE TABLE `follow` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `follower` varchar(255) NOT NULL,
+  `followee` varchar(255) NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_follower_followee` (`follower`,`followee`),
+  KEY `idx_follower_createdAt_id` (`follower`,`createdAt`,`id`),
+  KEY `idx_followee_createdAt_id` (`followee`,`createdAt`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,21 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加用户上传的文件.
+
+USE `miniblog`;
+
+CREATE TABLE `upload` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `hash` char(64) NOT NULL,
+  `filename` varchar(255) NOT NULL DEFAULT '',
+  `contentType` varchar(128) NOT NULL,
+  `size` bigint NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_username_hash` (`username`,`hash`),
+  KEY `idx_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,14 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加上传图片的尺寸和缩略图.
+-- 之前上传的文件没有缩略图，尺寸为 0.
+
+USE `miniblog`;
+
+ALTER TABLE `upload`
+  ADD COLUMN `width` int NOT NULL DEFAULT '0' AFTER `size`,
+  ADD COLUMN `height` int NOT NULL DEFAULT '0' AFTER `width`,
+  ADD COLUMN `variants` json DEFAULT NULL AFTER `height`;
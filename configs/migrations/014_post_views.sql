@@ -0,0 +1,20 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客的浏览次数和按天汇总的浏览次数.
+
+USE `miniblog`;
+
+ALTER TABLE `post` ADD COLUMN `viewCount` bigint unsigned NOT NULL DEFAULT '0' AFTER `moderateComments`;
+
+CREATE TABLE `post_daily_view` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `day` date NOT NULL,
+  `count` bigint unsigned NOT NULL DEFAULT '0',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_day` (`postID`,`day`),
+  KEY `idx_day` (`day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
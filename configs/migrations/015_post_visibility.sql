@@ -0,0 +1,15 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加博客的可见范围和关注审批.
+-- 已有的博客都是所有人可见的，已有的关注关系都视为已批准.
+
+USE `miniblog`;
+
+ALTER TABLE `post` ADD COLUMN `visibility` varchar(16) NOT NULL DEFAULT 'public' AFTER `status`;
+
+ALTER TABLE `follow` ADD COLUMN `approved` tinyint(1) NOT NULL DEFAULT '0' AFTER `followee`;
+
+UPDATE `follow` SET `approved` = 1;
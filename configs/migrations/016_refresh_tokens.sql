@@ -0,0 +1,23 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加刷新令牌.
+
+USE `miniblog`;
+
+CREATE TABLE `refresh_token` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `family` varchar(64) NOT NULL,
+  `tokenHash` char(64) NOT NULL,
+  `expiresAt` timestamp NOT NULL,
+  `rotatedAt` timestamp NULL DEFAULT NULL,
+  `revokedAt` timestamp NULL DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_tokenHash` (`tokenHash`),
+  KEY `idx_family` (`family`),
+  KEY `idx_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
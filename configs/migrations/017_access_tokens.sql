@@ -0,0 +1,25 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加个人访问令牌.
+
+USE `miniblog`;
+
+CREATE TABLE `access_token` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `tokenID` varchar(256) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `name` varchar(64) NOT NULL,
+  `scopes` varchar(255) NOT NULL DEFAULT '',
+  `tokenHash` char(64) NOT NULL,
+  `expiresAt` timestamp NOT NULL,
+  `lastUsedAt` timestamp NULL DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_tokenID` (`tokenID`),
+  UNIQUE KEY `idx_tokenHash` (`tokenHash`),
+  KEY `idx_username` (`username`),
+  KEY `idx_expiresAt` (`expiresAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
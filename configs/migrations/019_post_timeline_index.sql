@@ -0,0 +1,12 @@
+-- Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+-- Use of this source code is governed by a MIT style
+-- license that can be found in the LICENSE file. The original repo for
+-- this file is https://github.com/ischeng28/miniblog.
+
+-- 为已有的 miniblog 数据库添加时间线使用的索引.
+-- 时间线对每个作者按 username 和 status 定位之后按 (publishedAt, id) 倒序读取一页，不需要排序；
+-- 索引同时包含 visibility，过滤可见范围和读取 id、publishedAt 时不需要回表.
+
+USE `miniblog`;
+
+ALTER TABLE `post` ADD INDEX `idx_username_status_publishedAt_id_visibility` (`username`, `status`, `publishedAt`, `id`, `visibility`);
@@ -30,6 +30,100 @@ CREATE DATABASE /*!32312 IF NOT EXISTS*/ `miniblog` /*!40100 DEFAULT CHARACTER S
 
 USE `miniblog`;
 
+--
+-- Table structure for table `access_token`
+--
+
+DROP TABLE IF EXISTS `access_token`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `access_token` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `tokenID` varchar(256) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `name` varchar(64) NOT NULL,
+  `scopes` varchar(255) NOT NULL DEFAULT '',
+  `tokenHash` char(64) NOT NULL,
+  `expiresAt` timestamp NOT NULL,
+  `lastUsedAt` timestamp NULL DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_tokenID` (`tokenID`),
+  UNIQUE KEY `idx_tokenHash` (`tokenHash`),
+  KEY `idx_username` (`username`),
+  KEY `idx_expiresAt` (`expiresAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `comment`
+--
+
+DROP TABLE IF EXISTS `comment`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `comment` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `commentID` varchar(256) NOT NULL,
+  `postID` varchar(256) NOT NULL,
+  `parentID` varchar(256) NOT NULL DEFAULT '',
+  `rootID` varchar(256) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `status` varchar(16) NOT NULL DEFAULT 'approved',
+  `deleted` tinyint(1) NOT NULL DEFAULT '0',
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updatedAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `commentID` (`commentID`),
+  KEY `idx_postID_parentID_createdAt_id` (`postID`,`parentID`,`createdAt`,`id`),
+  KEY `idx_rootID` (`rootID`),
+  KEY `idx_parentID` (`parentID`),
+  KEY `idx_status_postID_createdAt_id` (`status`,`postID`,`createdAt`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `follow`
+--
+
+DROP TABLE IF EXISTS `follow`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `follow` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `follower` varchar(255) NOT NULL,
+  `followee` varchar(255) NOT NULL,
+  `approved` tinyint(1) NOT NULL DEFAULT '0',
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_follower_followee` (`follower`,`followee`),
+  KEY `idx_follower_createdAt_id` (`follower`,`createdAt`,`id`),
+  KEY `idx_followee_createdAt_id` (`followee`,`createdAt`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `login_challenge`
+--
+
+DROP TABLE IF EXISTS `login_challenge`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `login_challenge` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `tokenHash` char(64) NOT NULL,
+  `attempts` int unsigned NOT NULL DEFAULT '0',
+  `expiresAt` timestamp NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_tokenHash` (`tokenHash`),
+  KEY `idx_username` (`username`),
+  KEY `idx_expiresAt` (`expiresAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `post`
 --
@@ -43,14 +137,222 @@ CREATE TABLE `post` (
   `postID` varchar(256) NOT NULL,
   `title` varchar(256) NOT NULL,
   `content` longtext NOT NULL,
+  `revision` int unsigned NOT NULL DEFAULT '0',
+  `status` varchar(16) NOT NULL DEFAULT 'published',
+  `visibility` varchar(16) NOT NULL DEFAULT 'public',
+  `publishAt` timestamp NULL DEFAULT NULL,
+  `publishedAt` timestamp NULL DEFAULT NULL,
+  `claimedBy` varchar(64) NOT NULL DEFAULT '',
+  `claimedAt` timestamp NULL DEFAULT NULL,
+  `moderateComments` tinyint(1) NOT NULL DEFAULT '0',
+  `viewCount` bigint unsigned NOT NULL DEFAULT '0',
   `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updatedAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   UNIQUE KEY `postID` (`postID`),
-  KEY `idx_username` (`username`)
+  KEY `idx_username_createdAt_id` (`username`,`createdAt`,`id`),
+  KEY `idx_createdAt_id` (`createdAt`,`id`),
+  KEY `idx_status_publishAt` (`status`,`publishAt`),
+  KEY `idx_username_status_publishedAt_id_visibility` (`username`,`status`,`publishedAt`,`id`,`visibility`)
 ) ENGINE=InnoDB AUTO_INCREMENT=141 DEFAULT CHARSET=utf8mb3;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `post_daily_view`
+--
+
+DROP TABLE IF EXISTS `post_daily_view`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `post_daily_view` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `day` date NOT NULL,
+  `count` bigint unsigned NOT NULL DEFAULT '0',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_day` (`postID`,`day`),
+  KEY `idx_day` (`day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `post_reaction`
+--
+
+DROP TABLE IF EXISTS `post_reaction`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `post_reaction` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `kind` varchar(32) NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_username_kind` (`postID`,`username`,`kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `post_reaction_count`
+--
+
+DROP TABLE IF EXISTS `post_reaction_count`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `post_reaction_count` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `kind` varchar(32) NOT NULL,
+  `count` bigint NOT NULL DEFAULT '0',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_kind` (`postID`,`kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `post_revision`
+--
+
+DROP TABLE IF EXISTS `post_revision`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `post_revision` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `revision` int unsigned NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `title` varchar(256) NOT NULL,
+  `content` longtext NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_revision` (`postID`,`revision`),
+  KEY `idx_postID_createdAt_id` (`postID`,`createdAt`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `post_tag`
+--
+
+DROP TABLE IF EXISTS `post_tag`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `post_tag` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `postID` varchar(256) NOT NULL,
+  `tagID` bigint unsigned NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_postID_tagID` (`postID`,`tagID`),
+  KEY `idx_tagID` (`tagID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `recovery_code`
+--
+
+DROP TABLE IF EXISTS `recovery_code`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `recovery_code` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `codeHash` char(64) NOT NULL,
+  `usedAt` timestamp NULL DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_username_codeHash` (`username`,`codeHash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `refresh_token`
+--
+
+DROP TABLE IF EXISTS `refresh_token`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `refresh_token` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `family` varchar(64) NOT NULL,
+  `tokenHash` char(64) NOT NULL,
+  `expiresAt` timestamp NOT NULL,
+  `rotatedAt` timestamp NULL DEFAULT NULL,
+  `revokedAt` timestamp NULL DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_tokenHash` (`tokenHash`),
+  KEY `idx_family` (`family`),
+  KEY `idx_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `tag`
+--
+
+DROP TABLE IF EXISTS `tag`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `tag` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `slug` varchar(128) NOT NULL,
+  `name` varchar(128) NOT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `slug` (`slug`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `totp`
+--
+
+DROP TABLE IF EXISTS `totp`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `totp` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `secret` varchar(64) NOT NULL,
+  `enabledAt` timestamp NULL DEFAULT NULL,
+  `lastCounter` bigint NOT NULL DEFAULT '0',
+  `failedAttempts` int unsigned NOT NULL DEFAULT '0',
+  `lockedUntil` timestamp NULL DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updatedAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `upload`
+--
+
+DROP TABLE IF EXISTS `upload`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `upload` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `hash` char(64) NOT NULL,
+  `filename` varchar(255) NOT NULL DEFAULT '',
+  `contentType` varchar(128) NOT NULL,
+  `size` bigint NOT NULL,
+  `width` int NOT NULL DEFAULT '0',
+  `height` int NOT NULL DEFAULT '0',
+  `variants` json DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_username_hash` (`username`,`hash`),
+  KEY `idx_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `user`
 --
@@ -65,11 +367,12 @@ CREATE TABLE `user` (
   `nickname` varchar(30) NOT NULL,
   `email` varchar(256) NOT NULL,
   `phone` varchar(16) NOT NULL,
+  `postCount` int unsigned NOT NULL DEFAULT '0',
   `createdAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updatedAt` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   UNIQUE KEY `username` (`username`)
-) ENGINE=MyISAM AUTO_INCREMENT=27 DEFAULT CHARSET=utf8mb3;
+) ENGINE=InnoDB AUTO_INCREMENT=27 DEFAULT CHARSET=utf8mb3;
 /*!40101 SET character_set_client = @saved_cs_client */;
 /*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
 
@@ -6,13 +6,28 @@
 package biz
 
 import (
+	"github.com/ischeng28/miniblog/internal/miniblog/biz/comment"
+	"github.com/ischeng28/miniblog/internal/miniblog/biz/feed"
+	"github.com/ischeng28/miniblog/internal/miniblog/biz/post"
+	"github.com/ischeng28/miniblog/internal/miniblog/biz/tag"
+	"github.com/ischeng28/miniblog/internal/miniblog/biz/upload"
 	"github.com/ischeng28/miniblog/internal/miniblog/biz/user"
+	"github.com/ischeng28/miniblog/internal/miniblog/blob"
+	"github.com/ischeng28/miniblog/internal/miniblog/related"
+	"github.com/ischeng28/miniblog/internal/miniblog/search"
 	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/miniblog/trending"
+	"github.com/ischeng28/miniblog/internal/miniblog/view"
 )
 
 // IBiz 定义了 Biz 层需要实现的方法.
 type IBiz interface {
 	Users() user.UserBiz
+	Posts() post.PostBiz
+	Tags() tag.TagBiz
+	Comments() comment.CommentBiz
+	Feeds() feed.FeedBiz
+	Uploads() upload.UploadBiz
 }
 
 // 确保 biz 实现了 IBiz 接口.
@@ -35,3 +50,28 @@ func NewBiz(ds store.IStore) *biz {
 func (b *biz) Users() user.UserBiz {
 	return user.New(b.ds)
 }
+
+// Posts 返回一个实现了 PostBiz 接口的实例.
+func (b *biz) Posts() post.PostBiz {
+	return post.New(b.ds, search.S, view.S, trending.S, related.S)
+}
+
+// Tags 返回一个实现了 TagBiz 接口的实例.
+func (b *biz) Tags() tag.TagBiz {
+	return tag.New(b.ds)
+}
+
+// Comments 返回一个实现了 CommentBiz 接口的实例.
+func (b *biz) Comments() comment.CommentBiz {
+	return comment.New(b.ds)
+}
+
+// Feeds 返回一个实现了 FeedBiz 接口的实例.
+func (b *biz) Feeds() feed.FeedBiz {
+	return feed.New(b.ds)
+}
+
+// Uploads 返回一个实现了 UploadBiz 接口的实例.
+func (b *biz) Uploads() upload.UploadBiz {
+	return upload.New(b.ds, blob.S)
+}
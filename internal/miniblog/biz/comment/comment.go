@@ -0,0 +1,289 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package comment
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// CommentBiz 定义了 comment 模块在 biz 层所实现的方法.
+type CommentBiz interface {
+	Create(ctx context.Context, username, postID string, r *v1.CreateCommentRequest) (*v1.CreateCommentResponse, error)
+	Update(ctx context.Context, username, postID, commentID string, r *v1.UpdateCommentRequest) error
+	Delete(ctx context.Context, username, postID, commentID string) error
+	List(ctx context.Context, viewer, postID string, r *v1.ListCommentRequest) (*v1.ListCommentResponse, error)
+	ListQueue(ctx context.Context, owner string, r *v1.ListModerationQueueRequest) (*v1.ListModerationQueueResponse, error)
+	Moderate(ctx context.Context, owner string, r *v1.ModerateCommentsRequest) (*v1.ModerateCommentsResponse, error)
+}
+
+// CommentBiz 接口的实现.
+type commentBiz struct {
+	ds store.IStore
+}
+
+// 确保 commentBiz 实现了 CommentBiz 接口.
+var _ CommentBiz = (*commentBiz)(nil)
+
+// New 创建一个实现了 CommentBiz 接口的实例.
+func New(ds store.IStore) *commentBiz {
+	return &commentBiz{ds: ds}
+}
+
+// Create 是 CommentBiz 接口中 `Create` 方法的实现.
+// 博客开启评论审核时，除博客所有者以外的用户发表的评论需要审核通过后才会公开.
+func (b *commentBiz) Create(ctx context.Context, username, postID string, r *v1.CreateCommentRequest) (*v1.CreateCommentResponse, error) {
+	post, err := b.getPost(ctx, username, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	commentM := model.CommentM{PostID: postID, Username: username, Content: r.Content, Status: initialStatus(post, username)}
+	if r.ParentID != "" {
+		// 只能回复已经公开的评论
+		parent, err := b.getComment(ctx, postID, r.ParentID)
+		if err == nil && parent.Status != model.CommentStatusApproved {
+			err = errno.ErrCommentNotFound
+		}
+		if err != nil {
+			if errors.Is(err, errno.ErrCommentNotFound) {
+				return nil, errno.ErrInvalidParentComment
+			}
+
+			return nil, err
+		}
+
+		commentM.ParentID, commentM.RootID = parent.CommentID, parent.RootID
+	}
+
+	if err := b.ds.Comments().Create(ctx, &commentM); err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateCommentResponse{CommentID: commentM.CommentID, Status: commentM.Status}, nil
+}
+
+// Update 是 CommentBiz 接口中 `Update` 方法的实现，只有评论的作者可以修改评论.
+// 博客开启评论审核时，修改后的评论需要重新审核，防止审核通过后再改为垃圾内容.
+func (b *commentBiz) Update(ctx context.Context, username, postID, commentID string, r *v1.UpdateCommentRequest) error {
+	post, err := b.getPost(ctx, username, postID)
+	if err != nil {
+		return err
+	}
+
+	commentM, err := b.getComment(ctx, postID, commentID)
+	if err != nil {
+		return err
+	}
+
+	if commentM.Username != username {
+		return errno.ErrUnauthorized
+	}
+
+	commentM.Content = r.Content
+	if commentM.Status == model.CommentStatusApproved {
+		commentM.Status = initialStatus(post, username)
+	}
+
+	return b.ds.Comments().Update(ctx, commentM)
+}
+
+// Delete 是 CommentBiz 接口中 `Delete` 方法的实现，评论的作者和博客的所有者都可以删除评论.
+func (b *commentBiz) Delete(ctx context.Context, username, postID, commentID string) error {
+	post, err := b.getPost(ctx, username, postID)
+	if err != nil {
+		return err
+	}
+
+	commentM, err := b.getComment(ctx, postID, commentID)
+	if err != nil {
+		return err
+	}
+
+	if commentM.Username != username && post.Username != username {
+		return errno.ErrUnauthorized
+	}
+
+	return b.ds.TX(ctx, func(ds store.IStore) error {
+		return remove(ctx, ds, commentM)
+	})
+}
+
+// List 是 CommentBiz 接口中 `List` 方法的实现.
+// 分页的单位是讨论串，每个讨论串以树的形式返回全部回复，viewer 可以看到自己等待审核的评论.
+func (b *commentBiz) List(ctx context.Context, viewer, postID string, r *v1.ListCommentRequest) (*v1.ListCommentResponse, error) {
+	if _, err := b.getPost(ctx, viewer, postID); err != nil {
+		return nil, err
+	}
+
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	count, roots, next, err := b.ds.Comments().ListRoots(ctx, postID, viewer, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list comments from storage", "postID", postID, "err", err)
+		return nil, err
+	}
+
+	comments := make([]*v1.CommentInfo, 0, len(roots))
+	nodes := make(map[string]*v1.CommentInfo, len(roots))
+	rootIDs := make([]string, 0, len(roots))
+	for _, root := range roots {
+		info := toCommentInfo(root)
+		comments = append(comments, info)
+		nodes[root.CommentID] = info
+		rootIDs = append(rootIDs, root.CommentID)
+	}
+
+	if len(rootIDs) > 0 {
+		replies, err := b.ds.Comments().ListReplies(ctx, rootIDs, viewer)
+		if err != nil {
+			log.C(ctx).Errorw("Failed to list comment replies from storage", "postID", postID, "err", err)
+			return nil, err
+		}
+
+		// 回复按创建时间正序排列，被回复的评论总是先于回复出现
+		for _, reply := range replies {
+			parent, ok := nodes[reply.ParentID]
+			if !ok {
+				continue
+			}
+
+			info := toCommentInfo(reply)
+			parent.Replies = append(parent.Replies, info)
+			nodes[reply.CommentID] = info
+		}
+	}
+
+	return &v1.ListCommentResponse{TotalCount: count, NextCursor: next, Comments: comments}, nil
+}
+
+// getPost 查询 viewer 可以看到的博客，草稿和尚未发布的定时博客只对作者可见，已发布的博客按照可见范围判断.
+func (b *commentBiz) getPost(ctx context.Context, viewer, postID string) (*model.PostM, error) {
+	post, err := b.ds.Posts().Get(ctx, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	// 只有仅关注者可见的博客才需要查询 viewer 是否是作者批准的关注者
+	approved := false
+	if post.IsPublished() && post.Visibility == model.PostVisibilityFollowers && viewer != "" && viewer != post.Username {
+		if approved, err = b.ds.Follows().IsApproved(ctx, viewer, post.Username); err != nil {
+			return nil, err
+		}
+	}
+
+	if !post.VisibleTo(viewer, approved) {
+		return nil, errno.ErrPostNotFound
+	}
+
+	return post, nil
+}
+
+// getComment 查询属于指定博客并且没有被删除的评论.
+func (b *commentBiz) getComment(ctx context.Context, postID, commentID string) (*model.CommentM, error) {
+	comment, err := b.ds.Comments().Get(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrCommentNotFound
+		}
+
+		return nil, err
+	}
+
+	if comment.PostID != postID || comment.Deleted {
+		return nil, errno.ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// remove 删除评论，有回复的评论只清空内容并标记为已删除.
+// 删除没有回复的评论后，如果被回复的评论已经被标记为删除并且不再有回复，也一并删除.
+func remove(ctx context.Context, ds store.IStore, comment *model.CommentM) error {
+	for {
+		replies, err := ds.Comments().CountReplies(ctx, comment.CommentID)
+		if err != nil {
+			return err
+		}
+
+		if replies > 0 {
+			if comment.Deleted {
+				return nil
+			}
+
+			comment.Deleted, comment.Content = true, ""
+
+			return ds.Comments().Update(ctx, comment)
+		}
+
+		if err := ds.Comments().Delete(ctx, comment.CommentID); err != nil {
+			return err
+		}
+
+		if comment.ParentID == "" {
+			return nil
+		}
+
+		parent, err := ds.Comments().Get(ctx, comment.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !parent.Deleted {
+			return nil
+		}
+		comment = parent
+	}
+}
+
+// initialStatus 返回 username 在 post 下新发表或修改的评论的审核状态.
+func initialStatus(post *model.PostM, username string) string {
+	if post.ModerateComments && post.Username != username {
+		return model.CommentStatusPending
+	}
+
+	return model.CommentStatusApproved
+}
+
+// toCommentInfo 将 comment 数据库记录转换为接口返回的评论信息，已删除评论的作者和内容不会返回.
+func toCommentInfo(comment *model.CommentM) *v1.CommentInfo {
+	info := &v1.CommentInfo{
+		CommentID: comment.CommentID,
+		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		Username:  comment.Username,
+		Content:   comment.Content,
+		Deleted:   comment.Deleted,
+		Status:    comment.Status,
+		CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: comment.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if comment.Deleted {
+		info.Username = ""
+	}
+
+	return info
+}
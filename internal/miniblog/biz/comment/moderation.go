@@ -0,0 +1,66 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package comment
+
+import (
+	"context"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// maxModerateComments 是一次最多可以审核的评论数.
+const maxModerateComments = 100
+
+// moderationActions 是审核操作与审核后评论状态的对应关系.
+var moderationActions = map[string]string{
+	"approve": model.CommentStatusApproved,
+	"reject":  model.CommentStatusRejected,
+	"spam":    model.CommentStatusSpam,
+}
+
+// ListQueue 是 CommentBiz 接口中 `ListQueue` 方法的实现，返回 owner 的博客下等待审核的评论.
+func (b *commentBiz) ListQueue(ctx context.Context, owner string, r *v1.ListModerationQueueRequest) (*v1.ListModerationQueueResponse, error) {
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Status == "" {
+		r.Status = model.CommentStatusPending
+	}
+
+	count, list, next, err := b.ds.Comments().ListQueue(ctx, owner, r.Status, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list moderation queue from storage", "err", err)
+		return nil, err
+	}
+
+	comments := make([]*v1.CommentInfo, 0, len(list))
+	for _, item := range list {
+		comments = append(comments, toCommentInfo(item))
+	}
+
+	return &v1.ListModerationQueueResponse{TotalCount: count, NextCursor: next, Comments: comments}, nil
+}
+
+// Moderate 是 CommentBiz 接口中 `Moderate` 方法的实现，批量修改 owner 的博客下评论的审核状态.
+func (b *commentBiz) Moderate(ctx context.Context, owner string, r *v1.ModerateCommentsRequest) (*v1.ModerateCommentsResponse, error) {
+	if len(r.CommentIDs) == 0 || len(r.CommentIDs) > maxModerateComments {
+		return nil, errno.ErrInvalidParameter.SetMessage("commentIDs must contain 1 to 100 comments")
+	}
+
+	count, err := b.ds.Comments().SetStatus(ctx, owner, r.CommentIDs, moderationActions[r.Action])
+	if err != nil {
+		log.C(ctx).Errorw("Failed to update comment status", "commentIDs", r.CommentIDs, "err", err)
+		return nil, err
+	}
+
+	return &v1.ModerateCommentsResponse{Count: count}, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package feed
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	"github.com/ischeng28/miniblog/pkg/feed"
+	"github.com/ischeng28/miniblog/pkg/markdown"
+)
+
+// Options 包含生成订阅源所需的站点信息.
+type Options struct {
+	// Title 是站点名称.
+	Title string
+	// URL 是站点对外访问的根地址，用来生成订阅源中的绝对链接.
+	URL string
+	// Size 是订阅源中最多包含的博客数.
+	Size int
+}
+
+// FeedBiz 定义了 feed 模块在 biz 层所实现的方法.
+type FeedBiz interface {
+	Get(ctx context.Context, username, self string, opts *Options) (*feed.Feed, error)
+}
+
+// FeedBiz 接口的实现.
+type feedBiz struct {
+	ds store.IStore
+}
+
+// 确保 feedBiz 实现了 FeedBiz 接口.
+var _ FeedBiz = (*feedBiz)(nil)
+
+// New 创建一个实现了 FeedBiz 接口的实例.
+func New(ds store.IStore) *feedBiz {
+	return &feedBiz{ds: ds}
+}
+
+// Get 是 FeedBiz 接口中 `Get` 方法的实现，返回 username 最近发布的博客，username 为空时返回全站最近发布的博客.
+// self 是订阅源自身的路径.
+func (b *feedBiz) Get(ctx context.Context, username, self string, opts *Options) (*feed.Feed, error) {
+	base := strings.TrimRight(opts.URL, "/")
+	f := &feed.Feed{Title: opts.Title, Link: base + "/", Self: base + self}
+
+	// 没有博客时使用固定的更新时间，保证订阅源内容不变时 ETag 也不变
+	f.Updated = time.Unix(0, 0)
+	if username != "" {
+		user, err := b.ds.Users().Get(ctx, username)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errno.ErrUserNotFound
+			}
+
+			return nil, err
+		}
+
+		f.Title = user.Nickname + " - " + opts.Title
+		f.Link = base + known.BlogPath(username)
+		f.Updated = user.CreatedAt
+	}
+
+	page, err := core.NewPage("", opts.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// Viewer 为空，订阅源只包含已发布的公开博客
+	filter := &store.PostFilter{Username: username, Status: model.PostStatusPublished}
+	_, posts, _, err := b.ds.Posts().List(ctx, filter, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list feed posts from storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	for _, post := range posts {
+		doc, err := markdown.Render(post.Content)
+		if err != nil {
+			log.C(ctx).Errorw("Failed to render post content", "postID", post.PostID, "err", err)
+			return nil, err
+		}
+
+		published := post.CreatedAt
+		if post.PublishedAt != nil {
+			published = *post.PublishedAt
+		}
+
+		f.Entries = append(f.Entries, &feed.Entry{
+			Title:     post.Title,
+			Link:      base + known.PostPath(post.Username, post.PostID),
+			Author:    post.Username,
+			Published: published,
+			Updated:   post.UpdatedAt,
+			Content:   doc.HTML,
+		})
+
+		if post.UpdatedAt.After(f.Updated) {
+			f.Updated = post.UpdatedAt
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,532 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/related"
+	"github.com/ischeng28/miniblog/internal/miniblog/search"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/miniblog/trending"
+	"github.com/ischeng28/miniblog/internal/miniblog/view"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/markdown"
+	"github.com/ischeng28/miniblog/pkg/util/slug"
+)
+
+// defaultSearchLimit 是搜索默认返回的结果数.
+const defaultSearchLimit = 20
+
+// PostBiz 定义了 post 模块在 biz 层所实现的方法.
+type PostBiz interface {
+	Create(ctx context.Context, username string, r *v1.CreatePostRequest) (*v1.CreatePostResponse, error)
+	Update(ctx context.Context, username, postID string, r *v1.UpdatePostRequest) (*v1.UpdatePostResponse, error)
+	Delete(ctx context.Context, username, postID string) error
+	DeleteCollection(ctx context.Context, username string, postIDs []string) error
+	Get(ctx context.Context, viewer, postID string) (*v1.GetPostResponse, error)
+	List(ctx context.Context, viewer string, r *v1.ListPostRequest) (*v1.ListPostResponse, error)
+	Timeline(ctx context.Context, username string, r *v1.ListTimelineRequest) (*v1.ListTimelineResponse, error)
+	Search(ctx context.Context, r *v1.SearchPostRequest) (*v1.SearchPostResponse, error)
+	PublishScheduled(ctx context.Context, owner string, limit int) (int, error)
+	ListRevisions(ctx context.Context, username, postID string, r *v1.ListPostRevisionRequest) (*v1.ListPostRevisionResponse, error)
+	GetRevision(ctx context.Context, username, postID string, revision int) (*v1.GetPostRevisionResponse, error)
+	DiffRevisions(ctx context.Context, username, postID string, r *v1.DiffPostRevisionRequest) (*v1.DiffPostRevisionResponse, error)
+	RestoreRevision(ctx context.Context, username, postID string, revision int) (*v1.RestorePostRevisionResponse, error)
+	AddReaction(ctx context.Context, username, postID, kind string) error
+	RemoveReaction(ctx context.Context, username, postID, kind string) error
+	ListViews(ctx context.Context, username, postID string, r *v1.ListPostViewRequest) (*v1.ListPostViewResponse, error)
+	Trending(ctx context.Context, r *v1.ListTrendingRequest) (*v1.ListTrendingResponse, error)
+	RankTrending(ctx context.Context, opts *TrendingOptions) ([]trending.Entry, error)
+	Related(ctx context.Context, viewer, postID string, r *v1.ListRelatedRequest) (*v1.ListRelatedResponse, error)
+	BuildRelated(ctx context.Context) (int, error)
+}
+
+// PostBiz 接口的实现.
+// idx、views、ranking 和 related 在 run() 之外（例如命令行工具中）为 nil，此时跳过索引、统计和推荐.
+type postBiz struct {
+	ds      store.IStore
+	idx     *search.Index
+	views   *view.Counter
+	ranking *trending.Ranking
+	related *related.Index
+}
+
+// 确保 postBiz 实现了 PostBiz 接口.
+var _ PostBiz = (*postBiz)(nil)
+
+// New 创建一个实现了 PostBiz 接口的实例.
+func New(ds store.IStore, idx *search.Index, views *view.Counter, ranking *trending.Ranking, rel *related.Index) *postBiz {
+	return &postBiz{ds: ds, idx: idx, views: views, ranking: ranking, related: rel}
+}
+
+// Create 是 PostBiz 接口中 `Create` 方法的实现.
+func (b *postBiz) Create(ctx context.Context, username string, r *v1.CreatePostRequest) (*v1.CreatePostResponse, error) {
+	postM := model.PostM{Username: username, Title: r.Title, Content: r.Content, Revision: 1, Visibility: r.Visibility, ModerateComments: r.ModerateComments}
+	if postM.Visibility == "" {
+		postM.Visibility = model.PostVisibilityPublic
+	}
+	if err := setStatus(&postM, r.Status, r.PublishAt); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(r.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建博客、保存第一个修订和更新用户的博客数在同一个事务中完成，保证 postCount 准确
+	err = b.ds.TX(ctx, func(ds store.IStore) error {
+		if err := ds.Posts().Create(ctx, &postM); err != nil {
+			return err
+		}
+
+		if err := setTags(ctx, ds, postM.PostID, tags); err != nil {
+			return err
+		}
+
+		if err := ds.PostRevisions().Create(ctx, newRevision(&postM, username)); err != nil {
+			return err
+		}
+
+		if !postM.IsPublished() {
+			return nil
+		}
+
+		return ds.Users().IncrPostCount(ctx, username, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.index(ctx, &postM)
+
+	return &v1.CreatePostResponse{PostID: postM.PostID}, nil
+}
+
+// Delete 是 PostBiz 接口中 `Delete` 方法的实现.
+func (b *postBiz) Delete(ctx context.Context, username, postID string) error {
+	return b.DeleteCollection(ctx, username, []string{postID})
+}
+
+// DeleteCollection 是 PostBiz 接口中 `DeleteCollection` 方法的实现.
+func (b *postBiz) DeleteCollection(ctx context.Context, username string, postIDs []string) error {
+	var deleted []*model.PostM
+	err := b.ds.TX(ctx, func(ds store.IStore) (err error) {
+		deleted, err = ds.Posts().Delete(ctx, username, postIDs)
+		if err != nil || len(deleted) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(deleted))
+		for _, post := range deleted {
+			ids = append(ids, post.PostID)
+		}
+		if err := ds.PostRevisions().Delete(ctx, ids); err != nil {
+			return err
+		}
+
+		if err := ds.Tags().DeletePostTags(ctx, ids); err != nil {
+			return err
+		}
+
+		if err := ds.Comments().DeleteByPosts(ctx, ids); err != nil {
+			return err
+		}
+
+		if err := ds.Reactions().DeleteByPosts(ctx, ids); err != nil {
+			return err
+		}
+
+		if err := ds.Views().DeleteByPosts(ctx, ids); err != nil {
+			return err
+		}
+
+		// postCount 只统计已发布的博客
+		var published int64
+		for _, post := range deleted {
+			if post.IsPublished() {
+				published++
+			}
+		}
+		if published == 0 {
+			return nil
+		}
+
+		return ds.Users().IncrPostCount(ctx, username, -published)
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(deleted))
+	for _, post := range deleted {
+		ids = append(ids, post.PostID)
+	}
+	if b.idx != nil {
+		if err := b.idx.Delete(ids...); err != nil {
+			log.C(ctx).Errorw("Failed to delete posts from search index", "postIDs", ids, "err", err)
+		}
+	}
+	if b.related != nil {
+		b.related.Delete(ids...)
+	}
+
+	return nil
+}
+
+// Get 是 PostBiz 接口中 `Get` 方法的实现.
+// 草稿和尚未发布的定时博客只对作者可见，其他用户查询时返回博客不存在.
+func (b *postBiz) Get(ctx context.Context, viewer, postID string) (*v1.GetPostResponse, error) {
+	post, err := b.getVisible(ctx, viewer, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := markdown.Render(post.Content)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to render post content", "postID", postID, "err", err)
+		return nil, err
+	}
+
+	info := toPostInfo(post)
+	if err := b.fill(ctx, info); err != nil {
+		return nil, err
+	}
+
+	// 作者查看自己的博客不计入浏览次数
+	if b.views != nil && post.IsPublished() && viewer != post.Username {
+		b.views.Record(post.PostID)
+	}
+
+	resp := v1.GetPostResponse(*info)
+	resp.ContentHTML = doc.HTML
+	resp.TOC = make([]*v1.TOCItem, 0, len(doc.TOC))
+	for _, heading := range doc.TOC {
+		resp.TOC = append(resp.TOC, &v1.TOCItem{Level: heading.Level, ID: heading.ID, Title: heading.Title})
+	}
+
+	return &resp, nil
+}
+
+// Update 是 PostBiz 接口中 `Update` 方法的实现.
+// 每次修改都会生成一条新的修订记录，如果博客在读取之后被其他人修改则返回冲突错误，不会覆盖对方的修改.
+func (b *postBiz) Update(ctx context.Context, username, postID string, r *v1.UpdatePostRequest) (*v1.UpdatePostResponse, error) {
+	postM, err := b.getOwned(ctx, username, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Revision != nil && *r.Revision != postM.Revision {
+		return nil, errno.ErrPostRevisionConflict
+	}
+
+	wasPublished := postM.IsPublished()
+
+	if r.Title != nil {
+		postM.Title = *r.Title
+	}
+
+	if r.Content != nil {
+		postM.Content = *r.Content
+	}
+
+	if r.Visibility != nil {
+		postM.Visibility = *r.Visibility
+	}
+
+	if r.ModerateComments != nil {
+		postM.ModerateComments = *r.ModerateComments
+	}
+
+	switch {
+	case r.Status != nil:
+		err = setStatus(postM, *r.Status, r.PublishAt)
+	case r.PublishAt != nil:
+		// 只修改定时发布的时间
+		err = setStatus(postM, postM.Status, r.PublishAt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// tags 为 nil 表示不修改标签
+	var tags []*model.TagM
+	if r.Tags != nil {
+		if tags, err = normalizeTags(*r.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.save(ctx, username, postM, wasPublished, tags); err != nil {
+		return nil, err
+	}
+
+	return &v1.UpdatePostResponse{Revision: postM.Revision}, nil
+}
+
+// save 保存对博客的修改，同时生成一条新的修订记录并更新用户的博客数，tags 不为 nil 时替换博客的标签.
+// 只有数据库中博客的修订号没有变化时才会保存成功，否则返回冲突错误.
+func (b *postBiz) save(ctx context.Context, username string, post *model.PostM, wasPublished bool, tags []*model.TagM) error {
+	base := post.Revision
+	post.Revision++
+
+	err := b.ds.TX(ctx, func(ds store.IStore) error {
+		ok, err := ds.Posts().Update(ctx, post, base)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errno.ErrPostRevisionConflict
+		}
+
+		if err := ds.PostRevisions().Create(ctx, newRevision(post, username)); err != nil {
+			return err
+		}
+
+		if tags != nil {
+			if err := setTags(ctx, ds, post.PostID, tags); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case !wasPublished && post.IsPublished():
+			return ds.Users().IncrPostCount(ctx, post.Username, 1)
+		case wasPublished && !post.IsPublished():
+			return ds.Users().IncrPostCount(ctx, post.Username, -1)
+		}
+
+		return nil
+	})
+	if err != nil {
+		post.Revision = base
+		return err
+	}
+
+	b.index(ctx, post)
+
+	return nil
+}
+
+// getVisible 查询 viewer 可以看到的博客，viewer 看不到的博客返回博客不存在，不暴露博客是否存在.
+// 草稿和尚未发布的定时博客只对作者可见，已发布的博客按照可见范围判断，unlisted 博客知道链接的人都可以访问.
+func (b *postBiz) getVisible(ctx context.Context, viewer, postID string) (*model.PostM, error) {
+	post, err := b.ds.Posts().Get(ctx, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	ok, err := visibleTo(ctx, b.ds, post, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errno.ErrPostNotFound
+	}
+
+	return post, nil
+}
+
+// visibleTo 返回 viewer 是否可以访问博客，只有仅关注者可见的博客才需要查询 viewer 是否是作者批准的关注者.
+func visibleTo(ctx context.Context, ds store.IStore, post *model.PostM, viewer string) (bool, error) {
+	approved := false
+	if post.IsPublished() && post.Visibility == model.PostVisibilityFollowers && viewer != "" && viewer != post.Username {
+		var err error
+		if approved, err = ds.Follows().IsApproved(ctx, viewer, post.Username); err != nil {
+			return false, err
+		}
+	}
+
+	return post.VisibleTo(viewer, approved), nil
+}
+
+// getOwned 查询 username 拥有的博客，博客不存在时返回 ErrPostNotFound，不属于 username 时返回 ErrUnauthorized.
+// casbin 策略之外再校验一次所有者，防止策略缺失时越权操作.
+func (b *postBiz) getOwned(ctx context.Context, username, postID string) (*model.PostM, error) {
+	post, err := b.ds.Posts().Get(ctx, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	if post.Username != username {
+		return nil, errno.ErrUnauthorized
+	}
+
+	return post, nil
+}
+
+// List 是 PostBiz 接口中 `List` 方法的实现.
+// 草稿和尚未发布的定时博客只会出现在作者本人的查询结果中.
+func (b *postBiz) List(ctx context.Context, viewer string, r *v1.ListPostRequest) (*v1.ListPostResponse, error) {
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &store.PostFilter{
+		Username:    r.Author,
+		Viewer:      viewer,
+		Status:      r.Status,
+		TitlePrefix: r.TitlePrefix,
+		Tag:         slug.Make(r.Tag),
+		Since:       r.Since,
+		Until:       r.Until,
+	}
+	count, list, next, err := b.ds.Posts().List(ctx, filter, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list posts from storage", "err", err)
+		return nil, err
+	}
+
+	posts := make([]*v1.PostInfo, 0, len(list))
+	for _, item := range list {
+		posts = append(posts, toPostInfo(item))
+	}
+	if err := b.fill(ctx, posts...); err != nil {
+		return nil, err
+	}
+
+	return &v1.ListPostResponse{TotalCount: count, NextCursor: next, Posts: posts}, nil
+}
+
+// Search 是 PostBiz 接口中 `Search` 方法的实现.
+func (b *postBiz) Search(ctx context.Context, r *v1.SearchPostRequest) (*v1.SearchPostResponse, error) {
+	if r.Limit == 0 {
+		r.Limit = defaultSearchLimit
+	}
+
+	resp := &v1.SearchPostResponse{Results: []*v1.SearchResult{}}
+	if b.idx == nil {
+		return resp, nil
+	}
+
+	count, hits, err := b.idx.Search(ctx, &search.Options{Query: r.Q, Author: r.Author, Offset: r.Offset, Limit: r.Limit})
+	if err != nil {
+		log.C(ctx).Errorw("Failed to search posts", "q", r.Q, "err", err)
+		return nil, err
+	}
+
+	resp.TotalCount = count
+	for _, hit := range hits {
+		resp.Results = append(resp.Results, &v1.SearchResult{
+			PostID:     hit.PostID,
+			Username:   hit.Username,
+			Title:      hit.Title,
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+			CreatedAt:  hit.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return resp, nil
+}
+
+// fill 填充博客的标签和回应数量.
+func (b *postBiz) fill(ctx context.Context, posts ...*v1.PostInfo) error {
+	if err := b.fillTags(ctx, posts...); err != nil {
+		log.C(ctx).Errorw("Failed to list post tags from storage", "err", err)
+		return err
+	}
+
+	if err := b.fillReactions(ctx, posts...); err != nil {
+		log.C(ctx).Errorw("Failed to list post reactions from storage", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// index 将已发布的公开博客写入全文索引和相关博客索引，其它博客从索引中删除.
+// 索引失败不影响博客的写入，MySQL 中的数据是唯一可信来源，可以通过 `miniblog reindex` 重建索引.
+func (b *postBiz) index(ctx context.Context, post *model.PostM) {
+	b.indexRelated(ctx, post)
+	if b.idx == nil {
+		return
+	}
+
+	var err error
+	if post.IsPublic() {
+		err = b.idx.Index(post)
+	} else {
+		err = b.idx.Delete(post.PostID)
+	}
+
+	if err != nil {
+		log.C(ctx).Errorw("Failed to update search index", "postID", post.PostID, "err", err)
+	}
+}
+
+// setStatus 设置博客的发布状态，status 为空时表示立即发布.
+func setStatus(post *model.PostM, status string, publishAt *time.Time) error {
+	now := time.Now()
+
+	switch status {
+	case model.PostStatusDraft:
+		post.PublishAt, post.PublishedAt = nil, nil
+	case model.PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return errno.ErrInvalidPublishAt
+		}
+		post.PublishAt, post.PublishedAt = publishAt, nil
+	default:
+		status = model.PostStatusPublished
+		if !post.IsPublished() {
+			post.PublishedAt = &now
+		}
+		post.PublishAt = nil
+	}
+
+	post.Status = status
+	// 状态变化后之前的认领失效，由定时发布任务重新认领
+	post.ClaimedBy, post.ClaimedAt = "", nil
+
+	return nil
+}
+
+// toPostInfo 将 post 数据库记录转换为接口返回的博客信息.
+func toPostInfo(post *model.PostM) *v1.PostInfo {
+	return &v1.PostInfo{
+		Username:         post.Username,
+		PostID:           post.PostID,
+		Title:            post.Title,
+		Content:          post.Content,
+		Revision:         post.Revision,
+		Status:           post.Status,
+		Visibility:       post.Visibility,
+		PublishAt:        formatTime(post.PublishAt),
+		PublishedAt:      formatTime(post.PublishedAt),
+		ModerateComments: post.ModerateComments,
+		ViewCount:        post.ViewCount,
+		CreatedAt:        post.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        post.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// formatTime 格式化可能为空的时间，为空时返回空字符串.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+	"time"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// claimLease 是定时博客被认领后的租期，超过租期仍未发布的博客可以被其它实例重新认领.
+const claimLease = time.Minute
+
+// PublishScheduled 是 PostBiz 接口中 `PublishScheduled` 方法的实现.
+// 它认领最多 limit 条到达发布时间的定时博客并将其发布，返回发布成功的博客数.
+// owner 用来唯一标识当前 miniblog 实例，多个实例同时运行时每条博客只会被发布一次.
+func (b *postBiz) PublishScheduled(ctx context.Context, owner string, limit int) (int, error) {
+	claimed, err := b.ds.Posts().ClaimScheduled(ctx, owner, time.Now(), claimLease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, post := range claimed {
+		var ok bool
+		err := b.ds.TX(ctx, func(ds store.IStore) (err error) {
+			ok, err = ds.Posts().Publish(ctx, post, owner)
+			if err != nil || !ok {
+				return err
+			}
+
+			return ds.Users().IncrPostCount(ctx, post.Username, 1)
+		})
+		if err != nil {
+			log.C(ctx).Errorw("Failed to publish scheduled post", "postID", post.PostID, "err", err)
+			continue
+		}
+
+		// 博客在认领之后被作者修改或者被其它实例重新认领
+		if !ok {
+			continue
+		}
+
+		post.Status, post.PublishedAt = model.PostStatusPublished, post.PublishAt
+		b.index(ctx, post)
+		published++
+	}
+
+	return published, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// AddReaction 是 PostBiz 接口中 `AddReaction` 方法的实现，重复回应不会重复计数.
+func (b *postBiz) AddReaction(ctx context.Context, username, postID, kind string) error {
+	if !model.IsReactionKind(kind) {
+		return errno.ErrInvalidReactionKind
+	}
+
+	if _, err := b.getVisible(ctx, username, postID); err != nil {
+		return err
+	}
+
+	// 回应记录和回应数量在同一个事务中更新，读取博客时直接使用回应数量，不需要聚合查询
+	return b.ds.TX(ctx, func(ds store.IStore) error {
+		created, err := ds.Reactions().Create(ctx, &model.ReactionM{PostID: postID, Username: username, Kind: kind})
+		if err != nil || !created {
+			return err
+		}
+
+		return ds.Reactions().IncrCount(ctx, postID, kind, 1)
+	})
+}
+
+// RemoveReaction 是 PostBiz 接口中 `RemoveReaction` 方法的实现，取消不存在的回应不会返回错误.
+func (b *postBiz) RemoveReaction(ctx context.Context, username, postID, kind string) error {
+	if !model.IsReactionKind(kind) {
+		return errno.ErrInvalidReactionKind
+	}
+
+	if _, err := b.getVisible(ctx, username, postID); err != nil {
+		return err
+	}
+
+	return b.ds.TX(ctx, func(ds store.IStore) error {
+		deleted, err := ds.Reactions().Delete(ctx, postID, username, kind)
+		if err != nil || !deleted {
+			return err
+		}
+
+		return ds.Reactions().IncrCount(ctx, postID, kind, -1)
+	})
+}
+
+// fillReactions 查询博客的回应数量并填充到 posts 中.
+func (b *postBiz) fillReactions(ctx context.Context, posts ...*v1.PostInfo) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	counts, err := b.ds.Reactions().ListCounts(ctx, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Reactions = counts[post.PostID]
+		if post.Reactions == nil {
+			post.Reactions = map[string]int64{}
+		}
+	}
+
+	return nil
+}
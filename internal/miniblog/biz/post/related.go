@@ -0,0 +1,151 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/related"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+const (
+	// defaultRelatedLimit 是相关博客默认返回的博客数.
+	defaultRelatedLimit = 5
+	// relatedBatchSize 是构建相关博客索引时每批从 MySQL 读取的博客数.
+	relatedBatchSize = 100
+)
+
+// Related 是 PostBiz 接口中 `Related` 方法的实现，返回与指定博客最相似的已发布公开博客.
+// 只有公开博客在相关博客索引中，其它博客没有相关博客.
+func (b *postBiz) Related(ctx context.Context, viewer, postID string, r *v1.ListRelatedRequest) (*v1.ListRelatedResponse, error) {
+	post, err := b.getVisible(ctx, viewer, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultRelatedLimit
+	}
+
+	resp := &v1.ListRelatedResponse{Posts: make([]*v1.RelatedPostInfo, 0, limit)}
+	if b.related == nil {
+		return resp, nil
+	}
+
+	hits := b.related.Similar(post.PostID, limit)
+	if len(hits) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.PostID)
+	}
+
+	list, err := b.ds.Posts().Find(ctx, &store.PostFilter{PostIDs: ids, Status: model.PostStatusPublished})
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list related posts from storage", "err", err)
+		return nil, err
+	}
+
+	found := make(map[string]*v1.PostInfo, len(list))
+	posts := make([]*v1.PostInfo, 0, len(list))
+	for _, item := range list {
+		info := toPostInfo(item)
+		found[item.PostID] = info
+		posts = append(posts, info)
+	}
+	if err := b.fill(ctx, posts...); err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		if info, ok := found[hit.PostID]; ok {
+			resp.Posts = append(resp.Posts, &v1.RelatedPostInfo{PostInfo: info, Score: hit.Score})
+		}
+	}
+
+	return resp, nil
+}
+
+// BuildRelated 是 PostBiz 接口中 `BuildRelated` 方法的实现，按批次将所有已发布的公开博客写入相关博客索引.
+func (b *postBiz) BuildRelated(ctx context.Context) (int, error) {
+	if b.related == nil {
+		return 0, nil
+	}
+
+	page, _ := core.NewPage("", relatedBatchSize)
+	total := 0
+	for {
+		_, posts, next, err := b.ds.Posts().List(ctx, &store.PostFilter{Status: model.PostStatusPublished}, page)
+		if err != nil {
+			return total, err
+		}
+
+		docs, err := b.relatedDocuments(ctx, posts...)
+		if err != nil {
+			return total, err
+		}
+		b.related.Add(docs...)
+		total += len(docs)
+
+		if next == "" {
+			b.related.Refresh()
+			return total, nil
+		}
+		page.Cursor, _ = core.DecodeCursor(next)
+	}
+}
+
+// indexRelated 将已发布的公开博客写入相关博客索引，其它博客从索引中删除.
+func (b *postBiz) indexRelated(ctx context.Context, post *model.PostM) {
+	if b.related == nil {
+		return
+	}
+
+	if !post.IsPublic() {
+		b.related.Delete(post.PostID)
+		return
+	}
+
+	docs, err := b.relatedDocuments(ctx, post)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to update related posts index", "postID", post.PostID, "err", err)
+		return
+	}
+	b.related.Add(docs...)
+}
+
+// relatedDocuments 将博客转换为相关博客索引中的文档.
+// 正文直接使用 Markdown 源文本，不经过渲染，避免构建索引时渲染全部博客并挤占渲染结果的缓存.
+func (b *postBiz) relatedDocuments(ctx context.Context, posts ...*model.PostM) ([]*related.Document, error) {
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.PostID)
+	}
+
+	tags, err := b.ds.Tags().ListByPosts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*related.Document, 0, len(posts))
+	for _, post := range posts {
+		doc := &related.Document{PostID: post.PostID, Title: post.Title, Content: post.Content}
+		for _, tag := range tags[post.PostID] {
+			doc.Tags = append(doc.Tags, tag.Slug)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
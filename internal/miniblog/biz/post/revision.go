@@ -0,0 +1,157 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// ListRevisions 是 PostBiz 接口中 `ListRevisions` 方法的实现.
+func (b *postBiz) ListRevisions(ctx context.Context, username, postID string, r *v1.ListPostRevisionRequest) (*v1.ListPostRevisionResponse, error) {
+	if _, err := b.getOwned(ctx, username, postID); err != nil {
+		return nil, err
+	}
+
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	count, list, next, err := b.ds.PostRevisions().List(ctx, postID, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list post revisions from storage", "postID", postID, "err", err)
+		return nil, err
+	}
+
+	revisions := make([]*v1.PostRevisionInfo, 0, len(list))
+	for _, item := range list {
+		revisions = append(revisions, toRevisionInfo(item))
+	}
+
+	return &v1.ListPostRevisionResponse{TotalCount: count, NextCursor: next, Revisions: revisions}, nil
+}
+
+// GetRevision 是 PostBiz 接口中 `GetRevision` 方法的实现.
+func (b *postBiz) GetRevision(ctx context.Context, username, postID string, revision int) (*v1.GetPostRevisionResponse, error) {
+	if _, err := b.getOwned(ctx, username, postID); err != nil {
+		return nil, err
+	}
+
+	rev, err := b.getRevision(ctx, postID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := v1.GetPostRevisionResponse(*toRevisionInfo(rev))
+
+	return &resp, nil
+}
+
+// DiffRevisions 是 PostBiz 接口中 `DiffRevisions` 方法的实现.
+func (b *postBiz) DiffRevisions(ctx context.Context, username, postID string, r *v1.DiffPostRevisionRequest) (*v1.DiffPostRevisionResponse, error) {
+	if _, err := b.getOwned(ctx, username, postID); err != nil {
+		return nil, err
+	}
+
+	from, err := b.getRevision(ctx, postID, r.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := b.getRevision(ctx, postID, r.To)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(revisionText(from)),
+		B:        difflib.SplitLines(revisionText(to)),
+		FromFile: fmt.Sprintf("%s@%d", postID, from.Revision),
+		FromDate: from.CreatedAt.Format("2006-01-02 15:04:05"),
+		ToFile:   fmt.Sprintf("%s@%d", postID, to.Revision),
+		ToDate:   to.CreatedAt.Format("2006-01-02 15:04:05"),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.DiffPostRevisionResponse{From: from.Revision, To: to.Revision, Diff: diff}, nil
+}
+
+// RestoreRevision 是 PostBiz 接口中 `RestoreRevision` 方法的实现.
+// 恢复不会删除之后的修订，而是用旧修订的标题和正文生成一条新的修订.
+func (b *postBiz) RestoreRevision(ctx context.Context, username, postID string, revision int) (*v1.RestorePostRevisionResponse, error) {
+	post, err := b.getOwned(ctx, username, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := b.getRevision(ctx, postID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	post.Title, post.Content = rev.Title, rev.Content
+	if err := b.save(ctx, username, post, post.IsPublished(), nil); err != nil {
+		return nil, err
+	}
+
+	return &v1.RestorePostRevisionResponse{Revision: post.Revision}, nil
+}
+
+// getRevision 查询博客的指定修订，不存在时返回 ErrPostRevisionNotFound.
+func (b *postBiz) getRevision(ctx context.Context, postID string, revision int) (*model.PostRevisionM, error) {
+	rev, err := b.ds.PostRevisions().Get(ctx, postID, revision)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrPostRevisionNotFound
+		}
+
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+// newRevision 根据博客的当前内容创建一条修订记录，username 是产生该修订的用户.
+func newRevision(post *model.PostM, username string) *model.PostRevisionM {
+	return &model.PostRevisionM{
+		PostID:   post.PostID,
+		Revision: post.Revision,
+		Username: username,
+		Title:    post.Title,
+		Content:  post.Content,
+	}
+}
+
+// revisionText 返回用于对比的修订文本，第一行是标题，空一行之后是正文.
+func revisionText(rev *model.PostRevisionM) string {
+	return "# " + rev.Title + "\n\n" + rev.Content
+}
+
+// toRevisionInfo 将 post_revision 数据库记录转换为接口返回的修订信息.
+func toRevisionInfo(rev *model.PostRevisionM) *v1.PostRevisionInfo {
+	return &v1.PostRevisionInfo{
+		PostID:    rev.PostID,
+		Revision:  rev.Revision,
+		Username:  rev.Username,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		CreatedAt: rev.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
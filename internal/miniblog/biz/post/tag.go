@@ -0,0 +1,98 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/util/slug"
+)
+
+const (
+	// maxTags 是一篇博客最多可以添加的标签数.
+	maxTags = 10
+	// maxTagLength 是标签显示名的最大字符数.
+	maxTagLength = 32
+)
+
+// normalizeTags 规范化用户输入的标签名，slug 相同的标签只保留第一个.
+func normalizeTags(names []string) ([]*model.TagM, error) {
+	tags := make([]*model.TagM, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		// 合并连续的空白字符
+		name = strings.Join(strings.Fields(name), " ")
+		s := slug.Make(name)
+		if s == "" || utf8.RuneCountInString(name) > maxTagLength {
+			return nil, errno.ErrInvalidTags
+		}
+
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		tags = append(tags, &model.TagM{Slug: s, Name: name})
+	}
+
+	if len(tags) > maxTags {
+		return nil, errno.ErrInvalidTags
+	}
+
+	return tags, nil
+}
+
+// setTags 将博客的标签替换为 tags，需要在事务中调用.
+func setTags(ctx context.Context, ds store.IStore, postID string, tags []*model.TagM) error {
+	saved, err := ds.Tags().Ensure(ctx, tags)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]int64, len(saved))
+	for _, tag := range saved {
+		ids[tag.Slug] = tag.ID
+	}
+
+	// 保持用户输入的顺序
+	tagIDs := make([]int64, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, ids[tag.Slug])
+	}
+
+	return ds.Tags().SetPostTags(ctx, postID, tagIDs)
+}
+
+// fillTags 查询博客的标签并填充到 posts 中.
+func (b *postBiz) fillTags(ctx context.Context, posts ...*v1.PostInfo) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	tags, err := b.ds.Tags().ListByPosts(ctx, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Tags = make([]*v1.TagInfo, 0, len(tags[post.PostID]))
+		for _, tag := range tags[post.PostID] {
+			post.Tags = append(post.Tags, &v1.TagInfo{Slug: tag.Slug, Name: tag.Name})
+		}
+	}
+
+	return nil
+}
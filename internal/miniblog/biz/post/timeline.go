@@ -0,0 +1,63 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// Timeline 是 PostBiz 接口中 `Timeline` 方法的实现，按发布时间倒序返回 username 和其关注的用户已发布的博客.
+//
+// 时间线在读取时合并（fan-out on read）：每次请求查询关注列表，再对每个作者在索引上读取游标之后的一页博客并合并，
+// 单页的代价与关注数成正比，与每个作者发表了多少博客无关，所以关注数百个用户时也不需要对所有博客排序.
+// 定时发布的博客按照实际发布的时间而不是创建草稿的时间出现在时间线中.
+func (b *postBiz) Timeline(ctx context.Context, username string, r *v1.ListTimelineRequest) (*v1.ListTimelineResponse, error) {
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	followees, err := b.ds.Follows().Followees(ctx, username)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list followees from storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	// 与 PostFilter.Viewer 的规则一致：自己的博客全部可见，unlisted 博客不会出现在其他用户的时间线中
+	sources := make([]store.TimelineSource, 0, len(followees)+1)
+	sources = append(sources, store.TimelineSource{Username: username, Visibilities: []string{
+		model.PostVisibilityPublic, model.PostVisibilityUnlisted, model.PostVisibilityPrivate, model.PostVisibilityFollowers,
+	}})
+	for _, follow := range followees {
+		visibilities := []string{model.PostVisibilityPublic}
+		if follow.Approved {
+			visibilities = append(visibilities, model.PostVisibilityFollowers)
+		}
+		sources = append(sources, store.TimelineSource{Username: follow.Followee, Visibilities: visibilities})
+	}
+
+	list, next, err := b.ds.Posts().Timeline(ctx, sources, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list timeline posts from storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	posts := make([]*v1.PostInfo, 0, len(list))
+	for _, item := range list {
+		posts = append(posts, toPostInfo(item))
+	}
+	if err := b.fill(ctx, posts...); err != nil {
+		return nil, err
+	}
+
+	return &v1.ListTimelineResponse{NextCursor: next, Posts: posts}, nil
+}
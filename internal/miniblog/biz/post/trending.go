@@ -0,0 +1,160 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/miniblog/trending"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// 计算热门分数时每种行为的权重，评论和回应比浏览更能说明读者对博客的兴趣.
+const (
+	viewWeight     = 1
+	reactionWeight = 3
+	commentWeight  = 5
+)
+
+// defaultTrendingLimit 是热门博客默认返回的博客数.
+const defaultTrendingLimit = 20
+
+// TrendingOptions 包含计算热门博客排行的参数.
+type TrendingOptions struct {
+	// Window 是统计的时间范围，只统计最近 Window 内的浏览、回应和评论.
+	Window time.Duration
+	// HalfLife 是分数的半衰期，一次行为经过 HalfLife 之后贡献的分数减半.
+	HalfLife time.Duration
+	// Size 是排行中最多保存的博客数.
+	Size int
+}
+
+// Trending 是 PostBiz 接口中 `Trending` 方法的实现，返回最近一次计算的热门博客排行.
+func (b *postBiz) Trending(ctx context.Context, r *v1.ListTrendingRequest) (*v1.ListTrendingResponse, error) {
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultTrendingLimit
+	}
+
+	resp := &v1.ListTrendingResponse{Posts: make([]*v1.TrendingPostInfo, 0, limit)}
+	if b.ranking == nil {
+		return resp, nil
+	}
+
+	entries, updatedAt := b.ranking.Top(limit)
+	if !updatedAt.IsZero() {
+		resp.UpdatedAt = updatedAt.Format("2006-01-02 15:04:05")
+	}
+	if len(entries) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.PostID)
+	}
+
+	// 排行计算之后博客可能已经被删除、撤回或者不再公开，重新查询以过滤掉这些博客
+	list, err := b.ds.Posts().Find(ctx, &store.PostFilter{PostIDs: ids, Status: model.PostStatusPublished})
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list trending posts from storage", "err", err)
+		return nil, err
+	}
+
+	found := make(map[string]*v1.PostInfo, len(list))
+	posts := make([]*v1.PostInfo, 0, len(list))
+	for _, item := range list {
+		info := toPostInfo(item)
+		found[item.PostID] = info
+		posts = append(posts, info)
+	}
+	if err := b.fill(ctx, posts...); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if info, ok := found[entry.PostID]; ok {
+			resp.Posts = append(resp.Posts, &v1.TrendingPostInfo{PostInfo: info, Score: entry.Score})
+		}
+	}
+
+	return resp, nil
+}
+
+// RankTrending 是 PostBiz 接口中 `RankTrending` 方法的实现，计算热门博客排行.
+// 每次浏览、回应和评论按照权重计分，并按照发生的时间指数衰减，分数越高表示最近越受关注.
+// 浏览、回应和评论都在数据库中按博客和日期分组统计，读取的记录数与博客数和天数成正比，而不是与行为的次数成正比，
+// 所以衰减只精确到天，同一天的行为都按当天中午计算.
+func (b *postBiz) RankTrending(ctx context.Context, opts *TrendingOptions) ([]trending.Entry, error) {
+	now := time.Now()
+	since := now.Add(-opts.Window)
+	decay := func(at time.Time) float64 {
+		return math.Exp2(-max(now.Sub(at), 0).Hours() / opts.HalfLife.Hours())
+	}
+
+	scores := make(map[string]float64)
+
+	views, err := b.ds.Views().ListSince(ctx, time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, since.Location()))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range views {
+		scores[v.PostID] += viewWeight * float64(v.Count) * decay(v.Day.Add(12*time.Hour))
+	}
+
+	reactions, err := b.ds.Reactions().CountDailySince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reactions {
+		scores[r.PostID] += reactionWeight * float64(r.Count) * decay(r.Day.Add(12*time.Hour))
+	}
+
+	comments, err := b.ds.Comments().CountApprovedDailySince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		scores[c.PostID] += commentWeight * float64(c.Count) * decay(c.Day.Add(12*time.Hour))
+	}
+
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+
+	// 只有已发布的公开博客才能进入排行
+	list, err := b.ds.Posts().Find(ctx, &store.PostFilter{PostIDs: ids, Status: model.PostStatusPublished})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]trending.Entry, 0, len(list))
+	for _, post := range list {
+		entries = append(entries, trending.Entry{PostID: post.PostID, Score: scores[post.PostID]})
+	}
+
+	slices.SortFunc(entries, func(a, b trending.Entry) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.PostID, b.PostID)
+	})
+
+	return entries[:min(len(entries), opts.Size)], nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"context"
+	"time"
+
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// defaultViewDays 是默认返回的每日浏览次数的天数.
+const defaultViewDays = 30
+
+// ListViews 是 PostBiz 接口中 `ListViews` 方法的实现，只有博客所有者可以查看浏览统计.
+func (b *postBiz) ListViews(ctx context.Context, username, postID string, r *v1.ListPostViewRequest) (*v1.ListPostViewResponse, error) {
+	post, err := b.getOwned(ctx, username, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := r.Days
+	if days == 0 {
+		days = defaultViewDays
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, now.Location())
+
+	list, err := b.ds.Views().ListDaily(ctx, post.PostID, since)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list daily views from storage", "postID", postID, "err", err)
+		return nil, err
+	}
+
+	resp := &v1.ListPostViewResponse{ViewCount: post.ViewCount, Days: make([]*v1.DailyView, 0, len(list))}
+	for _, item := range list {
+		resp.Days = append(resp.Days, &v1.DailyView{Day: item.Day.Format("2006-01-02"), Count: item.Count})
+	}
+
+	return resp, nil
+}
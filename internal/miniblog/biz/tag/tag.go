@@ -0,0 +1,56 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package tag
+
+import (
+	"context"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/util/slug"
+)
+
+// defaultListLimit 是标签列表默认返回的标签数.
+const defaultListLimit = 100
+
+// TagBiz 定义了 tag 模块在 biz 层所实现的方法.
+type TagBiz interface {
+	List(ctx context.Context, r *v1.ListTagRequest) (*v1.ListTagResponse, error)
+}
+
+// TagBiz 接口的实现.
+type tagBiz struct {
+	ds store.IStore
+}
+
+// 确保 tagBiz 实现了 TagBiz 接口.
+var _ TagBiz = (*tagBiz)(nil)
+
+// New 创建一个实现了 TagBiz 接口的实例.
+func New(ds store.IStore) *tagBiz {
+	return &tagBiz{ds: ds}
+}
+
+// List 是 TagBiz 接口中 `List` 方法的实现.
+func (b *tagBiz) List(ctx context.Context, r *v1.ListTagRequest) (*v1.ListTagResponse, error) {
+	if r.Limit == 0 {
+		r.Limit = defaultListLimit
+	}
+
+	list, err := b.ds.Tags().List(ctx, slug.Make(r.Prefix), r.Limit)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list tags from storage", "err", err)
+		return nil, err
+	}
+
+	tags := make([]*v1.TagInfo, 0, len(list))
+	for _, item := range list {
+		tags = append(tags, &v1.TagInfo{Slug: item.Slug, Name: item.Name, PostCount: item.PostCount})
+	}
+
+	return &v1.ListTagResponse{Tags: tags}, nil
+}
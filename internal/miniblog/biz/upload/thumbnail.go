@@ -0,0 +1,94 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package upload
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	"github.com/ischeng28/miniblog/pkg/imaging"
+)
+
+// VariantKey 返回缩略图在 BlobStore 中的 key.
+func VariantKey(hash string, width int) string {
+	return hash + "-" + strconv.Itoa(width)
+}
+
+// isImage 返回是否需要去除该类型文件的元数据.
+func isImage(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// thumbnails 为图片生成比原图窄的各个尺寸的缩略图，并保存到 BlobStore 中.
+// GIF 可能是动画，不生成缩略图.
+// PNG 和带有透明像素的 WebP 生成 PNG 缩略图，其它图片生成 JPEG 缩略图.
+func (b *uploadBiz) thumbnails(ctx context.Context, hash string, img *imaging.Image, widths []int) ([]model.UploadVariant, error) {
+	if img.Format == "gif" {
+		return nil, nil
+	}
+
+	format := "jpeg"
+	if img.Format == "png" || (img.Format == "webp" && !imaging.Opaque(img.Image)) {
+		format = "png"
+	}
+
+	widths = slices.Clone(widths)
+	slices.Sort(widths)
+
+	var variants []model.UploadVariant
+	for _, width := range slices.Compact(widths) {
+		if width <= 0 || width >= img.Image.Bounds().Dx() {
+			continue
+		}
+
+		// 其他用户上传过相同的图片时缩略图已经存在
+		key := VariantKey(hash, width)
+		exists, err := b.blobs.Exists(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			var buf bytes.Buffer
+			if err := imaging.Encode(&buf, imaging.Resize(img.Image, width), format); err != nil {
+				return nil, err
+			}
+
+			if err := b.blobs.Put(ctx, key, &buf); err != nil {
+				return nil, err
+			}
+		}
+
+		variants = append(variants, model.UploadVariant{Width: width, Height: imaging.ScaledHeight(img.Image, width)})
+	}
+
+	return variants, nil
+}
+
+// sniff 根据内容判断文件类型，并将读取位置恢复到文件开头.
+func sniff(r io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return http.DetectContentType(head[:n]), nil
+}
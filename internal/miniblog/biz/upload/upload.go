@@ -0,0 +1,294 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/blob"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/imaging"
+)
+
+// maxFilenameLength 是保存的原始文件名的最大字符数.
+const maxFilenameLength = 255
+
+// Options 包含上传文件的限制.
+type Options struct {
+	// MaxSize 是单个文件的最大字节数.
+	MaxSize int64
+	// Quota 是每个用户上传的全部文件的最大字节数，相同的文件只计算一次.
+	Quota int64
+	// ContentTypes 是允许上传的文件类型，文件类型根据文件内容判断，而不是客户端声明的类型.
+	ContentTypes []string
+	// ThumbnailWidths 是为图片生成的缩略图宽度，只会生成比原图窄的缩略图.
+	ThumbnailWidths []int
+}
+
+// Media 是一个可以读取的上传文件.
+type Media struct {
+	Hash        string
+	Filename    string
+	ContentType string
+	Content     io.ReadSeekCloser
+}
+
+// UploadBiz 定义了 upload 模块在 biz 层所实现的方法.
+type UploadBiz interface {
+	Create(ctx context.Context, username, filename string, r io.Reader, opts *Options) (*v1.CreateUploadResponse, error)
+	Open(ctx context.Context, hash string, width int) (*Media, error)
+	Collect(ctx context.Context, grace time.Duration) (int, error)
+}
+
+// UploadBiz 接口的实现.
+type uploadBiz struct {
+	ds    store.IStore
+	blobs blob.BlobStore
+}
+
+// 确保 uploadBiz 实现了 UploadBiz 接口.
+var _ UploadBiz = (*uploadBiz)(nil)
+
+// New 创建一个实现了 UploadBiz 接口的实例.
+func New(ds store.IStore, blobs blob.BlobStore) *uploadBiz {
+	return &uploadBiz{ds: ds, blobs: blobs}
+}
+
+// Create 是 UploadBiz 接口中 `Create` 方法的实现.
+// 文件以内容的 SHA-256 摘要命名，用户重复上传相同的文件时直接返回已有的记录.
+// 图片会先去除 EXIF 等元数据再计算摘要，并生成各个尺寸的缩略图.
+func (b *uploadBiz) Create(ctx context.Context, username, filename string, r io.Reader, opts *Options) (*v1.CreateUploadResponse, error) {
+	// 多读取一个字节，用来判断文件是否超过大小限制
+	data, err := io.ReadAll(io.LimitReader(r, opts.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > opts.MaxSize {
+		return nil, errno.ErrUploadTooLarge
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !slices.Contains(opts.ContentTypes, contentType) {
+		return nil, errno.ErrUnsupportedMediaType
+	}
+
+	var img *imaging.Image
+	if isImage(contentType) {
+		if img, err = imaging.Strip(data); err != nil {
+			if errors.Is(err, imaging.ErrInvalidImage) {
+				return nil, errno.ErrInvalidImage
+			}
+
+			return nil, err
+		}
+		data = img.Data
+	}
+
+	sum := sha256.Sum256(data)
+	uploadM := &model.UploadM{
+		Username:    username,
+		Hash:        hex.EncodeToString(sum[:]),
+		Filename:    truncate(filepath.Base(filename), maxFilenameLength),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+
+	// 在写入文件之前先检查一次，避免为重复或者超过配额的文件生成缩略图，写入记录时会在事务中再次检查
+	existing, err := check(ctx, b.ds, uploadM, opts.Quota)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	if img != nil {
+		bounds := img.Image.Bounds()
+		uploadM.Width, uploadM.Height = bounds.Dx(), bounds.Dy()
+		if uploadM.Variants, err = b.thumbnails(ctx, uploadM.Hash, img, opts.ThumbnailWidths); err != nil {
+			log.C(ctx).Errorw("Failed to create thumbnails", "hash", uploadM.Hash, "err", err)
+			return nil, err
+		}
+	}
+
+	if err := b.blobs.Put(ctx, uploadM.Hash, bytes.NewReader(data)); err != nil {
+		log.C(ctx).Errorw("Failed to store uploaded file", "hash", uploadM.Hash, "err", err)
+		return nil, err
+	}
+
+	resp := toResponse(uploadM)
+	err = b.ds.TX(ctx, func(ds store.IStore) error {
+		// 锁定用户记录，使同一个用户的并发上传依次检查配额和写入记录
+		if err := ds.Users().Lock(ctx, username); err != nil {
+			return err
+		}
+
+		existing, err := check(ctx, ds, uploadM, opts.Quota)
+		if err != nil || existing != nil {
+			resp = existing
+			return err
+		}
+
+		_, err = ds.Uploads().Create(ctx, uploadM)
+
+		return err
+	})
+	if err != nil {
+		// 文件以内容命名，其他用户可能正在上传相同的文件，所以这里不删除已经保存的文件，由 Collect 清理
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// check 返回用户已经上传过的相同文件，没有上传过时检查上传 uploadM 之后是否超过配额.
+func check(ctx context.Context, ds store.IStore, uploadM *model.UploadM, quota int64) (*v1.CreateUploadResponse, error) {
+	existing, err := ds.Uploads().Get(ctx, uploadM.Username, uploadM.Hash)
+	if err == nil {
+		return toResponse(existing), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	total, err := ds.Uploads().TotalSize(ctx, uploadM.Username)
+	if err != nil {
+		return nil, err
+	}
+	if total+uploadM.Size > quota {
+		return nil, errno.ErrUploadQuotaExceeded
+	}
+
+	return nil, nil
+}
+
+// Open 是 UploadBiz 接口中 `Open` 方法的实现，width 大于 0 时打开对应宽度的缩略图.
+func (b *uploadBiz) Open(ctx context.Context, hash string, width int) (*Media, error) {
+	uploadM, err := b.ds.Uploads().GetByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrMediaNotFound
+		}
+
+		return nil, err
+	}
+
+	media := &Media{Hash: uploadM.Hash, Filename: uploadM.Filename, ContentType: uploadM.ContentType}
+	if width > 0 {
+		media.Hash = VariantKey(uploadM.Hash, width)
+	}
+
+	media.Content, err = b.blobs.Open(ctx, media.Hash)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, errno.ErrMediaNotFound
+		}
+
+		return nil, err
+	}
+
+	// 缩略图的格式不一定与原图相同，根据内容判断
+	if width > 0 {
+		if media.ContentType, err = sniff(media.Content); err != nil {
+			media.Content.Close()
+			return nil, err
+		}
+	}
+
+	return media, nil
+}
+
+// Collect 是 UploadBiz 接口中 `Collect` 方法的实现，删除保存超过 grace 并且没有被任何 upload 记录引用的文件和缩略图，
+// 返回删除的文件数. 上传文件时先保存文件再写入记录，grace 需要远大于一次上传的耗时，
+// 重复上传已经存在的文件时 BlobStore.Put 会刷新文件的保存时间，遍历之前已经保存的上传文件不会被删除.
+func (b *uploadBiz) Collect(ctx context.Context, grace time.Duration) (int, error) {
+	before := time.Now().Add(-grace)
+
+	var stale []string
+	err := b.blobs.Walk(ctx, func(key string, modTime time.Time) error {
+		if modTime.Before(before) {
+			stale = append(stale, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	referenced := make(map[string]bool)
+	for _, key := range stale {
+		// 缩略图的 key 以原图的摘要开头
+		hash, _, _ := strings.Cut(key, "-")
+		ok, checked := referenced[hash]
+		if !checked {
+			_, err := b.ds.Uploads().GetByHash(ctx, hash)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return deleted, err
+			}
+			ok = err == nil
+			referenced[hash] = ok
+		}
+		if ok {
+			continue
+		}
+
+		if err := b.blobs.Delete(ctx, key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// toResponse 将 upload 记录转换为接口返回参数.
+func toResponse(uploadM *model.UploadM) *v1.CreateUploadResponse {
+	resp := &v1.CreateUploadResponse{
+		Hash:        uploadM.Hash,
+		URL:         known.MediaPath(uploadM.Hash),
+		Filename:    uploadM.Filename,
+		ContentType: uploadM.ContentType,
+		Size:        uploadM.Size,
+		Width:       uploadM.Width,
+		Height:      uploadM.Height,
+	}
+
+	for _, variant := range uploadM.Variants {
+		resp.Variants = append(resp.Variants, &v1.UploadVariant{
+			Width:  variant.Width,
+			Height: variant.Height,
+			URL:    known.MediaPath(VariantKey(uploadM.Hash, variant.Width)),
+		})
+	}
+
+	return resp
+}
+
+// truncate 将 s 截断为最多 n 个字符.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+
+	return s
+}
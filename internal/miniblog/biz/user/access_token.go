@@ -0,0 +1,168 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/token"
+)
+
+const (
+	// maxAccessTokens 是每个用户最多可以同时拥有的未过期的个人访问令牌数量.
+	maxAccessTokens = 50
+	// touchInterval 是更新令牌最后使用时间的最小间隔.
+	touchInterval = time.Minute
+)
+
+// CreateAccessToken 是 UserBiz 接口中 `CreateAccessToken` 方法的实现，为用户创建个人访问令牌.
+// 令牌的 scope 不能超出 caller 的 scope，避免通过个人访问令牌创建权限更大的令牌.
+func (b *userBiz) CreateAccessToken(ctx context.Context, caller *token.Claims, username string, r *v1.CreateAccessTokenRequest) (*v1.CreateAccessTokenResponse, error) {
+	if caller.Subject != username {
+		return nil, errno.ErrUnauthorized
+	}
+
+	scopes := slices.Clone(r.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+	for _, scope := range scopes {
+		if !slices.Contains(known.AllScopes, scope) || !caller.HasScope(scope) {
+			return nil, errno.ErrInvalidScope.SetMessage("Scope %q was invalid.", scope)
+		}
+	}
+
+	now := time.Now()
+	value := known.AccessTokenPrefix + randomToken(32)
+	at := &model.AccessTokenM{
+		Username:  username,
+		Name:      r.Name,
+		Scopes:    strings.Join(scopes, " "),
+		TokenHash: hashToken(value),
+		ExpiresAt: now.AddDate(0, 0, r.ExpiresInDays),
+	}
+	err := b.ds.TX(ctx, func(ds store.IStore) error {
+		// 锁定用户记录，使同一个用户的并发请求依次检查令牌数量，已过期的令牌不计入数量
+		if err := ds.Users().Lock(ctx, username); err != nil {
+			return err
+		}
+
+		count, err := ds.AccessTokens().CountActive(ctx, username, now)
+		if err != nil {
+			return err
+		}
+		if count >= maxAccessTokens {
+			return errno.ErrAccessTokenLimitExceeded
+		}
+
+		if err := ds.AccessTokens().Create(ctx, at); err != nil {
+			log.C(ctx).Errorw("Failed to create access token in storage", "username", username, "err", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateAccessTokenResponse{AccessTokenInfo: *accessTokenInfo(at), Token: value}, nil
+}
+
+// ListAccessTokens 是 UserBiz 接口中 `ListAccessTokens` 方法的实现，用户只能查看自己的个人访问令牌.
+func (b *userBiz) ListAccessTokens(ctx context.Context, caller, username string) (*v1.ListAccessTokenResponse, error) {
+	if caller != username {
+		return nil, errno.ErrUnauthorized
+	}
+
+	list, err := b.ds.AccessTokens().List(ctx, username)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list access tokens from storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	tokens := make([]*v1.AccessTokenInfo, 0, len(list))
+	for _, item := range list {
+		tokens = append(tokens, accessTokenInfo(item))
+	}
+
+	return &v1.ListAccessTokenResponse{TotalCount: int64(len(tokens)), AccessTokens: tokens}, nil
+}
+
+// DeleteAccessToken 是 UserBiz 接口中 `DeleteAccessToken` 方法的实现，删除之后令牌立即失效.
+func (b *userBiz) DeleteAccessToken(ctx context.Context, caller, username, tokenID string) error {
+	if caller != username {
+		return errno.ErrUnauthorized
+	}
+
+	ok, err := b.ds.AccessTokens().Delete(ctx, username, tokenID)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to delete access token from storage", "username", username, "tokenID", tokenID, "err", err)
+		return err
+	}
+	if !ok {
+		return errno.ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+// VerifyAccessToken 是 UserBiz 接口中 `VerifyAccessToken` 方法的实现，验证个人访问令牌并返回对应的 claims.
+// 令牌的最后使用时间最多每 touchInterval 更新一次，更新失败不影响认证结果.
+func (b *userBiz) VerifyAccessToken(ctx context.Context, value string) (*token.Claims, error) {
+	at, err := b.ds.AccessTokens().GetByHash(ctx, hashToken(value))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrTokenInvalid
+		}
+
+		return nil, err
+	}
+
+	now := time.Now()
+	if !now.Before(at.ExpiresAt) {
+		return nil, errno.ErrTokenInvalid
+	}
+
+	if err := b.ds.AccessTokens().Touch(ctx, at.ID, now, touchInterval); err != nil {
+		log.C(ctx).Errorw("Failed to update access token last used time", "tokenID", at.TokenID, "err", err)
+	}
+
+	claims := token.NewClaims(at.Username, []string{known.RoleUser}, strings.Fields(at.Scopes))
+	claims.ID = at.TokenID
+	claims.IssuedAt = jwt.NewNumericDate(at.CreatedAt)
+	claims.ExpiresAt = jwt.NewNumericDate(at.ExpiresAt)
+
+	return claims, nil
+}
+
+// accessTokenInfo 将 model.AccessTokenM 转换为 v1.AccessTokenInfo.
+func accessTokenInfo(at *model.AccessTokenM) *v1.AccessTokenInfo {
+	info := &v1.AccessTokenInfo{
+		TokenID:   at.TokenID,
+		Name:      at.Name,
+		Scopes:    strings.Fields(at.Scopes),
+		ExpiresAt: at.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt: at.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if at.LastUsedAt != nil {
+		info.LastUsedAt = at.LastUsedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return info
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// Follow 是 UserBiz 接口中 `Follow` 方法的实现，重复关注不会返回错误.
+func (b *userBiz) Follow(ctx context.Context, follower, followee string) error {
+	if follower == followee {
+		return errno.ErrFollowSelf
+	}
+
+	if err := b.exists(ctx, followee); err != nil {
+		return err
+	}
+
+	return b.ds.Follows().Create(ctx, &model.FollowM{Follower: follower, Followee: followee})
+}
+
+// Unfollow 是 UserBiz 接口中 `Unfollow` 方法的实现，取消不存在的关注不会返回错误.
+func (b *userBiz) Unfollow(ctx context.Context, follower, followee string) error {
+	return b.ds.Follows().Delete(ctx, follower, followee)
+}
+
+// ListFollowers 是 UserBiz 接口中 `ListFollowers` 方法的实现.
+func (b *userBiz) ListFollowers(ctx context.Context, username string, r *v1.ListFollowRequest) (*v1.ListFollowResponse, error) {
+	if err := b.exists(ctx, username); err != nil {
+		return nil, err
+	}
+
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	count, list, next, err := b.ds.Follows().ListFollowers(ctx, username, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list followers from storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	users := make([]*v1.FollowInfo, 0, len(list))
+	for _, item := range list {
+		users = append(users, &v1.FollowInfo{Username: item.Follower, FollowedAt: item.CreatedAt.Format("2006-01-02 15:04:05"), Approved: item.Approved})
+	}
+
+	return &v1.ListFollowResponse{TotalCount: count, NextCursor: next, Users: users}, nil
+}
+
+// ListFollowing 是 UserBiz 接口中 `ListFollowing` 方法的实现.
+func (b *userBiz) ListFollowing(ctx context.Context, username string, r *v1.ListFollowRequest) (*v1.ListFollowResponse, error) {
+	if err := b.exists(ctx, username); err != nil {
+		return nil, err
+	}
+
+	page, err := core.NewPage(r.Cursor, r.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	count, list, next, err := b.ds.Follows().ListFollowing(ctx, username, page)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to list following from storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	users := make([]*v1.FollowInfo, 0, len(list))
+	for _, item := range list {
+		users = append(users, &v1.FollowInfo{Username: item.Followee, FollowedAt: item.CreatedAt.Format("2006-01-02 15:04:05"), Approved: item.Approved})
+	}
+
+	return &v1.ListFollowResponse{TotalCount: count, NextCursor: next, Users: users}, nil
+}
+
+// ApproveFollower 是 UserBiz 接口中 `ApproveFollower` 方法的实现，批准或者撤销 follower 对 followee 的关注.
+// 用户只能管理自己的关注者，批准的关注者可以看到 followee 仅关注者可见的博客，撤销批准不会取消关注.
+func (b *userBiz) ApproveFollower(ctx context.Context, username, followee, follower string, approved bool) error {
+	if username != followee {
+		return errno.ErrUnauthorized
+	}
+
+	ok, err := b.ds.Follows().Approve(ctx, follower, followee, approved)
+	if err != nil {
+		log.C(ctx).Errorw("Failed to approve follower in storage", "followee", followee, "follower", follower, "err", err)
+		return err
+	}
+	if !ok {
+		return errno.ErrFollowNotFound
+	}
+
+	return nil
+}
+
+// exists 检查用户是否存在，不存在时返回 ErrUserNotFound.
+func (b *userBiz) exists(ctx context.Context, username string) error {
+	if _, err := b.ds.Users().Get(ctx, username); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errno.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/token"
+)
+
+// AuthOptions 包含签发令牌的参数.
+type AuthOptions struct {
+	// RefreshTokenTTL 是刷新令牌的有效期，每次刷新都会签发一个新的刷新令牌并重新计算有效期.
+	RefreshTokenTTL time.Duration
+	// ChallengeTTL 是启用两步验证的用户通过密码验证之后得到的挑战令牌的有效期.
+	ChallengeTTL time.Duration
+	// TOTPIssuer 是身份验证器应用中显示的服务名称.
+	TOTPIssuer string
+}
+
+// Refresh 是 UserBiz 接口中 `Refresh` 方法的实现，使用刷新令牌换取新的 JWT Token 和刷新令牌.
+// 刷新令牌只能使用一次，已经轮换的刷新令牌再次被使用时说明令牌可能已经泄露，撤销整个令牌族，
+// 攻击者和用户手中的令牌同时失效，用户需要重新登录.
+func (b *userBiz) Refresh(ctx context.Context, r *v1.RefreshTokenRequest, opts *AuthOptions) (*v1.RefreshTokenResponse, error) {
+	now := time.Now()
+	rt, err := b.ds.RefreshTokens().GetByHash(ctx, hashToken(r.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrRefreshTokenInvalid
+		}
+
+		return nil, err
+	}
+
+	if rt.RevokedAt != nil || !now.Before(rt.ExpiresAt) {
+		return nil, errno.ErrRefreshTokenInvalid
+	}
+
+	var resp *v1.LoginResponse
+	err = b.ds.TX(ctx, func(ds store.IStore) error {
+		// 轮换失败说明令牌已经被其它请求使用
+		ok, err := ds.RefreshTokens().Rotate(ctx, rt.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errno.ErrRefreshTokenReused
+		}
+
+		resp, err = issue(ctx, ds, rt.Username, rt.Family, opts)
+
+		return err
+	})
+	if errors.Is(err, errno.ErrRefreshTokenReused) {
+		log.C(ctx).Warnw("Refresh token reused, revoking token family", "username", rt.Username, "family", rt.Family)
+		if err := b.ds.RefreshTokens().RevokeFamily(ctx, rt.Family, now); err != nil {
+			return nil, err
+		}
+
+		return nil, errno.ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return (*v1.RefreshTokenResponse)(resp), nil
+}
+
+// Logout 是 UserBiz 接口中 `Logout` 方法的实现，撤销刷新令牌所在的令牌族，不存在的刷新令牌不会返回错误.
+// 已经签发的 JWT Token 在过期之前仍然有效.
+func (b *userBiz) Logout(ctx context.Context, r *v1.LogoutRequest) error {
+	rt, err := b.ds.RefreshTokens().GetByHash(ctx, hashToken(r.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	return b.ds.RefreshTokens().RevokeFamily(ctx, rt.Family, time.Now())
+}
+
+// issue 为用户签发 JWT Token 和属于令牌族 family 的刷新令牌，family 为空时创建新的令牌族.
+func issue(ctx context.Context, ds store.IStore, username, family string, opts *AuthOptions) (*v1.LoginResponse, error) {
+	t, err := token.Sign(token.NewClaims(username, []string{known.RoleUser}, known.AllScopes))
+	if err != nil {
+		return nil, errno.ErrSignToken
+	}
+
+	if family == "" {
+		family = randomToken(16)
+	}
+
+	refreshToken := randomToken(32)
+	rt := &model.RefreshTokenM{
+		Username:  username,
+		Family:    family,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(opts.RefreshTokenTTL),
+	}
+	if err := ds.RefreshTokens().Create(ctx, rt); err != nil {
+		return nil, err
+	}
+
+	return &v1.LoginResponse{Token: t, RefreshToken: refreshToken}, nil
+}
+
+// randomToken 返回 n 字节随机数的 base64url 编码.
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashToken 返回令牌的 SHA-256 摘要，令牌本身是高熵的随机数，不需要加盐或者使用慢哈希.
+func hashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,364 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/totp"
+)
+
+const (
+	// totpSkew 是验证一次性密码时允许的时间步长偏差，用来容忍客户端和服务端的时钟误差.
+	totpSkew = 1
+	// recoveryCodeCount 是每次生成的恢复码数量.
+	recoveryCodeCount = 10
+	// maxChallengeAttempts 是每个挑战令牌允许的最大验证次数.
+	maxChallengeAttempts = 5
+	// maxFailedAttempts 是每个用户允许的最大连续失败次数，重新登录获得新的挑战令牌不会清零.
+	maxFailedAttempts = 10
+	// lockoutDuration 是连续失败次数达到 maxFailedAttempts 之后锁定两步验证的时间.
+	lockoutDuration = 15 * time.Minute
+)
+
+// recoveryEncoding 是恢复码使用的编码，只包含小写字母和数字，便于用户抄写.
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// EnrollTOTP 是 UserBiz 接口中 `EnrollTOTP` 方法的实现，为用户生成新的密钥，用户验证之后才会启用两步验证.
+// 重复调用会替换还没有完成绑定的密钥.
+func (b *userBiz) EnrollTOTP(ctx context.Context, caller, username string, opts *AuthOptions) (*v1.EnrollTOTPResponse, error) {
+	if caller != username {
+		return nil, errno.ErrUnauthorized
+	}
+
+	if t, err := b.ds.TOTPs().Get(ctx, username); err == nil && t.Enabled() {
+		return nil, errno.ErrTOTPAlreadyEnabled
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.ds.TX(ctx, func(ds store.IStore) error {
+		if err := ds.TOTPs().DeletePending(ctx, username); err != nil {
+			return err
+		}
+
+		return ds.TOTPs().Create(ctx, &model.TOTPM{Username: username, Secret: secret})
+	})
+	if err != nil {
+		log.C(ctx).Errorw("Failed to create totp in storage", "username", username, "err", err)
+		return nil, err
+	}
+
+	return &v1.EnrollTOTPResponse{Secret: secret, URI: totp.URI(opts.TOTPIssuer, username, secret)}, nil
+}
+
+// VerifyTOTP 是 UserBiz 接口中 `VerifyTOTP` 方法的实现，验证身份验证器生成的一次性密码，启用两步验证并生成恢复码.
+// 启用之后撤销用户所有的刷新令牌，其它设备上的会话需要重新登录并通过两步验证.
+func (b *userBiz) VerifyTOTP(ctx context.Context, caller, username string, r *v1.VerifyTOTPRequest) (*v1.VerifyTOTPResponse, error) {
+	if caller != username {
+		return nil, errno.ErrUnauthorized
+	}
+
+	t, err := b.getTOTP(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if t.Enabled() {
+		return nil, errno.ErrTOTPAlreadyEnabled
+	}
+
+	counter, ok := totp.Validate(t.Secret, r.Code, time.Now(), totpSkew)
+	if !ok {
+		return nil, errno.ErrTOTPCodeIncorrect
+	}
+
+	var codes []string
+	err = b.ds.TX(ctx, func(ds store.IStore) error {
+		enabled, err := ds.TOTPs().Enable(ctx, t.ID, counter, time.Now())
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return errno.ErrTOTPAlreadyEnabled
+		}
+
+		if err := ds.RefreshTokens().RevokeByUser(ctx, username, time.Now()); err != nil {
+			return err
+		}
+
+		codes, err = resetRecoveryCodes(ctx, ds, username)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.VerifyTOTPResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP 是 UserBiz 接口中 `DisableTOTP` 方法的实现，使用一次性密码或者恢复码关闭两步验证.
+func (b *userBiz) DisableTOTP(ctx context.Context, caller, username string, r *v1.DisableTOTPRequest) error {
+	if caller != username {
+		return errno.ErrUnauthorized
+	}
+
+	t, err := b.getTOTP(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !t.Enabled() {
+		return errno.ErrTOTPNotEnabled
+	}
+
+	if err := b.verify(ctx, t, r.Code, true); err != nil {
+		return err
+	}
+
+	return b.ds.TX(ctx, func(ds store.IStore) error {
+		if err := ds.TOTPs().Delete(ctx, username); err != nil {
+			return err
+		}
+
+		return ds.RecoveryCodes().DeleteByUser(ctx, username)
+	})
+}
+
+// RegenerateRecoveryCodes 是 UserBiz 接口中 `RegenerateRecoveryCodes` 方法的实现，使用一次性密码重新生成恢复码.
+func (b *userBiz) RegenerateRecoveryCodes(ctx context.Context, caller, username string, r *v1.RegenerateRecoveryCodesRequest) (*v1.RegenerateRecoveryCodesResponse, error) {
+	if caller != username {
+		return nil, errno.ErrUnauthorized
+	}
+
+	t, err := b.getTOTP(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Enabled() {
+		return nil, errno.ErrTOTPNotEnabled
+	}
+
+	if err := b.verify(ctx, t, r.Code, false); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = b.ds.TX(ctx, func(ds store.IStore) error {
+		codes, err = resetRecoveryCodes(ctx, ds, username)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.RegenerateRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// LoginTwoFactor 是 UserBiz 接口中 `LoginTwoFactor` 方法的实现，使用挑战令牌和一次性密码或者恢复码换取 Token.
+// 每个挑战令牌只能成功使用一次，验证次数达到 maxChallengeAttempts 之后失效，用户需要重新使用密码登录.
+// 重新登录不会清零用户的连续失败次数，达到 maxFailedAttempts 之后两步验证被锁定 lockoutDuration.
+func (b *userBiz) LoginTwoFactor(ctx context.Context, r *v1.LoginTwoFactorRequest, opts *AuthOptions) (*v1.LoginResponse, error) {
+	ch, err := b.ds.LoginChallenges().GetByHash(ctx, hashToken(r.ChallengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrLoginChallengeInvalid
+		}
+
+		return nil, err
+	}
+	if !time.Now().Before(ch.ExpiresAt) {
+		_, _ = b.ds.LoginChallenges().Delete(ctx, ch.ID)
+
+		return nil, errno.ErrLoginChallengeInvalid
+	}
+
+	// 验证之前先预留一次验证次数，同一个挑战令牌的并发请求也不能超过 maxChallengeAttempts 次
+	ok, err := b.ds.LoginChallenges().Attempt(ctx, ch.ID, maxChallengeAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		_, _ = b.ds.LoginChallenges().Delete(ctx, ch.ID)
+
+		return nil, errno.ErrLoginChallengeInvalid
+	}
+
+	t, err := b.getTOTP(ctx, ch.Username)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Enabled() {
+		return nil, errno.ErrLoginChallengeInvalid
+	}
+
+	if err := b.verify(ctx, t, r.Code, true); err != nil {
+		if errors.Is(err, errno.ErrTOTPCodeIncorrect) {
+			log.C(ctx).Warnw("Two-factor login failed", "username", ch.Username, "attempts", ch.Attempts+1)
+		}
+
+		return nil, err
+	}
+
+	// 删除成功说明挑战令牌没有被其它请求使用过
+	ok, err = b.ds.LoginChallenges().Delete(ctx, ch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errno.ErrLoginChallengeInvalid
+	}
+
+	return issue(ctx, b.ds, ch.Username, "", opts)
+}
+
+// PurgeLoginChallenges 是 UserBiz 接口中 `PurgeLoginChallenges` 方法的实现，删除已经过期的挑战令牌.
+func (b *userBiz) PurgeLoginChallenges(ctx context.Context) (int64, error) {
+	return b.ds.LoginChallenges().DeleteExpired(ctx, time.Now())
+}
+
+// challenge 为通过密码验证的用户创建挑战令牌.
+func challenge(ctx context.Context, ds store.IStore, username string, opts *AuthOptions) (*v1.LoginResponse, error) {
+	value := randomToken(32)
+	ch := &model.LoginChallengeM{
+		Username:  username,
+		TokenHash: hashToken(value),
+		ExpiresAt: time.Now().Add(opts.ChallengeTTL),
+	}
+	if err := ds.LoginChallenges().Create(ctx, ch); err != nil {
+		return nil, err
+	}
+
+	return &v1.LoginResponse{TwoFactorRequired: true, ChallengeToken: value}, nil
+}
+
+// getTOTP 查询用户的 totp 记录，用户没有开始绑定时返回 ErrTOTPNotEnabled.
+func (b *userBiz) getTOTP(ctx context.Context, username string) (*model.TOTPM, error) {
+	t, err := b.ds.TOTPs().Get(ctx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errno.ErrTOTPNotEnabled
+		}
+
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// verify 为用户预留一次验证次数之后调用 verifyCode，用户连续失败的次数过多时返回 ErrTOTPLocked.
+func (b *userBiz) verify(ctx context.Context, t *model.TOTPM, code string, allowRecovery bool) error {
+	ok, err := b.ds.TOTPs().Attempt(ctx, t.ID, time.Now(), maxFailedAttempts, lockoutDuration)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.C(ctx).Warnw("Two-factor verification locked", "username", t.Username)
+		return errno.ErrTOTPLocked
+	}
+
+	if err := verifyCode(ctx, b.ds, t, code, allowRecovery); err != nil {
+		return err
+	}
+
+	// 清零失败不影响本次验证的结果，最多使用户提前被锁定
+	if err := b.ds.TOTPs().ResetAttempts(ctx, t.ID); err != nil {
+		log.C(ctx).Errorw("Failed to reset totp failed attempts", "username", t.Username, "err", err)
+	}
+
+	return nil
+}
+
+// verifyCode 验证一次性密码，allowRecovery 为 true 时也接受没有使用过的恢复码.
+// 每个一次性密码和恢复码都只能使用一次.
+func verifyCode(ctx context.Context, ds store.IStore, t *model.TOTPM, code string, allowRecovery bool) error {
+	if len(code) == totp.Digits {
+		counter, ok := totp.Validate(t.Secret, code, time.Now(), totpSkew)
+		if !ok {
+			return errno.ErrTOTPCodeIncorrect
+		}
+
+		used, err := ds.TOTPs().UseCounter(ctx, t.ID, counter)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errno.ErrTOTPCodeIncorrect
+		}
+
+		return nil
+	}
+
+	if !allowRecovery {
+		return errno.ErrTOTPCodeIncorrect
+	}
+
+	used, err := ds.RecoveryCodes().Use(ctx, t.Username, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errno.ErrTOTPCodeIncorrect
+	}
+	log.C(ctx).Infow("Recovery code used", "username", t.Username)
+
+	return nil
+}
+
+// resetRecoveryCodes 删除用户所有的恢复码并生成新的恢复码，返回恢复码本身.
+func resetRecoveryCodes(ctx context.Context, ds store.IStore, username string) ([]string, error) {
+	if err := ds.RecoveryCodes().DeleteByUser(ctx, username); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	models := make([]*model.RecoveryCodeM, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code := newRecoveryCode()
+		codes = append(codes, code)
+		models = append(models, &model.RecoveryCodeM{Username: username, CodeHash: hashRecoveryCode(code)})
+	}
+	if err := ds.RecoveryCodes().Create(ctx, models); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// newRecoveryCode 生成一个形如 "xxxxx-xxxxx" 的恢复码，包含 50 位随机数.
+func newRecoveryCode() string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	s := recoveryEncoding.EncodeToString(b)[:10]
+
+	return s[:5] + "-" + s[5:]
+}
+
+// hashRecoveryCode 返回恢复码的摘要，忽略大小写、空格和分隔符.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	return hashToken(code)
+}
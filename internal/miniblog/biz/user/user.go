@@ -9,9 +9,9 @@ import (
 	"context"
 	"errors"
 	"github.com/ischeng28/miniblog/pkg/auth"
-	"github.com/ischeng28/miniblog/pkg/token"
 	"gorm.io/gorm"
 	"regexp"
+	"time"
 
 	"github.com/jinzhu/copier"
 
@@ -19,14 +19,32 @@ import (
 	"github.com/ischeng28/miniblog/internal/pkg/errno"
 	"github.com/ischeng28/miniblog/internal/pkg/model"
 	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/token"
 )
 
 // UserBiz 定义了 user 模块在 biz 层所实现的方法.
 type UserBiz interface {
 	Create(ctx context.Context, r *v1.CreateUserRequest) error
-	Login(ctx context.Context, r *v1.LoginRequest) (*v1.LoginResponse, error)
+	Login(ctx context.Context, r *v1.LoginRequest, opts *AuthOptions) (*v1.LoginResponse, error)
+	Refresh(ctx context.Context, r *v1.RefreshTokenRequest, opts *AuthOptions) (*v1.RefreshTokenResponse, error)
+	Logout(ctx context.Context, r *v1.LogoutRequest) error
 	ChangePassword(ctx context.Context, username string, r *v1.ChangePasswordRequest) error
 	Get(ctx context.Context, username string) (*v1.GetUserResponse, error)
+	Follow(ctx context.Context, follower, followee string) error
+	Unfollow(ctx context.Context, follower, followee string) error
+	ListFollowers(ctx context.Context, username string, r *v1.ListFollowRequest) (*v1.ListFollowResponse, error)
+	ListFollowing(ctx context.Context, username string, r *v1.ListFollowRequest) (*v1.ListFollowResponse, error)
+	ApproveFollower(ctx context.Context, username, followee, follower string, approved bool) error
+	CreateAccessToken(ctx context.Context, caller *token.Claims, username string, r *v1.CreateAccessTokenRequest) (*v1.CreateAccessTokenResponse, error)
+	ListAccessTokens(ctx context.Context, caller, username string) (*v1.ListAccessTokenResponse, error)
+	DeleteAccessToken(ctx context.Context, caller, username, tokenID string) error
+	VerifyAccessToken(ctx context.Context, value string) (*token.Claims, error)
+	EnrollTOTP(ctx context.Context, caller, username string, opts *AuthOptions) (*v1.EnrollTOTPResponse, error)
+	VerifyTOTP(ctx context.Context, caller, username string, r *v1.VerifyTOTPRequest) (*v1.VerifyTOTPResponse, error)
+	DisableTOTP(ctx context.Context, caller, username string, r *v1.DisableTOTPRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, caller, username string, r *v1.RegenerateRecoveryCodesRequest) (*v1.RegenerateRecoveryCodesResponse, error)
+	LoginTwoFactor(ctx context.Context, r *v1.LoginTwoFactorRequest, opts *AuthOptions) (*v1.LoginResponse, error)
+	PurgeLoginChallenges(ctx context.Context) (int64, error)
 }
 
 // UserBiz 接口的实现.
@@ -55,11 +73,13 @@ func (b *userBiz) ChangePassword(ctx context.Context, username string, r *v1.Cha
 	if err := b.ds.Users().Update(ctx, userM); err != nil {
 		return err
 	}
-	return nil
+
+	// 修改密码后撤销所有刷新令牌，其它设备需要使用新密码重新登录
+	return b.ds.RefreshTokens().RevokeByUser(ctx, username, time.Now())
 }
 
 // Login 是UserBiz接口中`Login`方法的实现
-func (b *userBiz) Login(ctx context.Context, r *v1.LoginRequest) (*v1.LoginResponse, error) {
+func (b *userBiz) Login(ctx context.Context, r *v1.LoginRequest, opts *AuthOptions) (*v1.LoginResponse, error) {
 	user, err := b.ds.Users().Get(ctx, r.Username)
 	if err != nil {
 		return nil, errno.ErrUserNotFound
@@ -68,12 +88,17 @@ func (b *userBiz) Login(ctx context.Context, r *v1.LoginRequest) (*v1.LoginRespo
 	if err := auth.Compare(user.Password, r.Password); err != nil {
 		return nil, errno.ErrPasswordIncorrect
 	}
-	// 如果匹配成功，说明登录成功，签发token并返回
-	t, err := token.Sign(r.Username)
-	if err != nil {
-		return nil, errno.ErrSignToken
+	// 启用了两步验证的用户需要使用挑战令牌和一次性密码换取token
+	t, err := b.ds.TOTPs().Get(ctx, user.Username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
 	}
-	return &v1.LoginResponse{Token: t}, nil
+	if err == nil && t.Enabled() {
+		return challenge(ctx, b.ds, user.Username, opts)
+	}
+
+	// 如果匹配成功，说明登录成功，签发token和新令牌族的刷新令牌并返回
+	return issue(ctx, b.ds, user.Username, "", opts)
 }
 
 // Create 是 UserBiz 接口中 `Create` 方法的实现.
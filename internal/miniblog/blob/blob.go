@@ -0,0 +1,66 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package blob 定义了上传文件的存储接口，以及基于本地文件系统的实现.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	once sync.Once
+	// S 全局变量，方便其它包直接调用已初始化好的 S 实例.
+	S BlobStore
+)
+
+// ErrNotFound 表示 key 对应的内容不存在.
+var ErrNotFound = errors.New("blob: not found")
+
+// BlobStore 定义了上传文件的存储后端需要实现的方法.
+// key 由调用方生成，只包含字母、数字、'-'、'_' 和 '.'，相同的 key 总是对应相同的内容.
+type BlobStore interface {
+	// Put 保存 key 对应的内容，key 已经存在时不会覆盖，只刷新内容的保存时间.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open 打开 key 对应的内容，不存在时返回 ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Exists 返回 key 对应的内容是否存在.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete 删除 key 对应的内容，不存在时不返回错误.
+	Delete(ctx context.Context, key string) error
+	// Walk 对保存的每个 key 调用 fn，modTime 是内容最近一次被 Put 的时间，fn 返回错误时停止遍历并返回该错误.
+	Walk(ctx context.Context, fn func(key string, modTime time.Time) error) error
+}
+
+// Init 设置全局的 BlobStore 实例.
+func Init(s BlobStore) BlobStore {
+	// 确保 S 只被初始化一次
+	once.Do(func() {
+		S = s
+	})
+
+	return S
+}
+
+// validKey 返回 key 是否可以安全地用作存储路径.
+func validKey(key string) bool {
+	if len(key) < 2 || key[0] == '.' {
+		return false
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
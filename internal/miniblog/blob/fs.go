@@ -0,0 +1,150 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FSStore 是基于本地文件系统的 BlobStore 实现.
+// 文件按照 key 的前两个字符分目录保存，避免单个目录下的文件过多.
+type FSStore struct {
+	dir string
+}
+
+// 确保 FSStore 实现了 BlobStore 接口.
+var _ BlobStore = (*FSStore)(nil)
+
+// NewFSStore 创建一个把文件保存在 dir 目录下的 FSStore，目录不存在时自动创建.
+func NewFSStore(dir string) (*FSStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FSStore{dir: dir}, nil
+}
+
+// Put 是 BlobStore 接口中 `Put` 方法的实现.
+// 内容先写入同目录下的临时文件，写入完成后再重命名，避免读取到不完整的文件.
+func (s *FSStore) Put(ctx context.Context, key string, r io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	// 内容已经存在时刷新修改时间，使正在被重新上传的文件不会被当作长时间未被引用的文件清理
+	if _, err := os.Stat(name); err == nil {
+		now := time.Now()
+		return os.Chtimes(name, now, now)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// Open 是 BlobStore 接口中 `Open` 方法的实现.
+func (s *FSStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+// Exists 是 BlobStore 接口中 `Exists` 方法的实现.
+func (s *FSStore) Exists(ctx context.Context, key string) (bool, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Delete 是 BlobStore 接口中 `Delete` 方法的实现.
+func (s *FSStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Walk 是 BlobStore 接口中 `Walk` 方法的实现，文件的修改时间就是内容的保存时间.
+func (s *FSStore) Walk(ctx context.Context, fn func(key string, modTime time.Time) error) error {
+	return filepath.WalkDir(s.dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// 跳过目录和 Put 正在写入的临时文件
+		if d.IsDir() || !validKey(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return fn(d.Name(), info.ModTime())
+	})
+}
+
+// path 返回 key 对应的文件路径.
+func (s *FSStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("blob: invalid key %q", key)
+	}
+
+	return filepath.Join(s.dir, key[:2], key), nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// defaultChallengeCleanupInterval 是未配置 auth.challenge-cleanup-interval 时删除过期挑战令牌的间隔.
+const defaultChallengeCleanupInterval = 10 * time.Minute
+
+// startChallengeCleaner 在后台定期删除过期的两步验证挑战令牌，ctx 被取消时退出.
+// 用户通过密码验证之后没有完成两步验证时，挑战令牌不会被使用，也不会在登录流程中被删除.
+func startChallengeCleaner(ctx context.Context) {
+	interval := viper.GetDuration("auth.challenge-cleanup-interval")
+	if interval <= 0 {
+		interval = defaultChallengeCleanupInterval
+	}
+
+	log.Infow("Start login challenge cleaner", "interval", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			n, err := biz.NewBiz(store.S).Users().PurgeLoginChallenges(ctx)
+			if err != nil {
+				log.Errorw("Failed to purge expired login challenges", "err", err)
+				continue
+			}
+			if n > 0 {
+				log.Infow("Purged expired login challenges", "count", n)
+			}
+		}
+	}()
+}
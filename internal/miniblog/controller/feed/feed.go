@@ -0,0 +1,109 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	feedbiz "github.com/ischeng28/miniblog/internal/miniblog/biz/feed"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/pkg/feed"
+)
+
+// cacheControl 允许订阅阅读器和代理缓存订阅源 5 分钟，过期后通过 ETag 重新验证.
+const cacheControl = "public, max-age=300"
+
+// FeedController 是 feed 模块在 Controller 层的实现，用来输出 Atom 和 RSS 订阅源.
+type FeedController struct {
+	b    biz.IBiz
+	opts *feedbiz.Options
+}
+
+// New 创建一个 feed controller.
+func New(ds store.IStore, opts *feedbiz.Options) *FeedController {
+	return &FeedController{b: biz.NewBiz(ds), opts: opts}
+}
+
+// SiteAtom 返回全站的 Atom 订阅源.
+func (ctrl *FeedController) SiteAtom(c *gin.Context) {
+	log.C(c).Infow("Get site atom feed function called")
+
+	ctrl.serve(c, "", "application/atom+xml; charset=utf-8", (*feed.Feed).Atom)
+}
+
+// SiteRSS 返回全站的 RSS 订阅源.
+func (ctrl *FeedController) SiteRSS(c *gin.Context) {
+	log.C(c).Infow("Get site rss feed function called")
+
+	ctrl.serve(c, "", "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+// UserAtom 返回指定用户的 Atom 订阅源.
+func (ctrl *FeedController) UserAtom(c *gin.Context) {
+	log.C(c).Infow("Get user atom feed function called")
+
+	ctrl.serve(c, c.Param("name"), "application/atom+xml; charset=utf-8", (*feed.Feed).Atom)
+}
+
+// UserRSS 返回指定用户的 RSS 订阅源.
+func (ctrl *FeedController) UserRSS(c *gin.Context) {
+	log.C(c).Infow("Get user rss feed function called")
+
+	ctrl.serve(c, c.Param("name"), "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+// serve 生成订阅源并按 If-None-Match 请求头返回 304 或者完整内容.
+func (ctrl *FeedController) serve(c *gin.Context, username, contentType string, encode func(*feed.Feed) ([]byte, error)) {
+	f, err := ctrl.b.Feeds().Get(c, username, c.Request.URL.Path, ctrl.opts)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	data, err := encode(f)
+	if err != nil {
+		log.C(c).Errorw("Failed to encode feed", "err", err)
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+
+	if matchETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// matchETag 返回 If-None-Match 请求头是否匹配 etag，按 RFC 7232 使用弱比较.
+func matchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package comment
+
+import (
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+)
+
+// CommentController 是 comment 模块在 Controller 层的实现，用来处理评论模块的请求.
+// 评论的作者可以修改和删除自己的评论，博客所有者可以删除博客下的任意评论，所有权在 biz 层校验.
+type CommentController struct {
+	b biz.IBiz
+}
+
+// New 创建一个 comment controller.
+func New(ds store.IStore) *CommentController {
+	return &CommentController{b: biz.NewBiz(ds)}
+}
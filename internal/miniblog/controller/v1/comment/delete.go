@@ -0,0 +1,28 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package comment
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// Delete 删除指定的评论.
+func (ctrl *CommentController) Delete(c *gin.Context) {
+	log.C(c).Infow("Delete comment function called")
+
+	postID, commentID := c.Param("postID"), c.Param("commentID")
+	if err := ctrl.b.Comments().Delete(c, c.GetString(known.XUsernameKey), postID, commentID); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
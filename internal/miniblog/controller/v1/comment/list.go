@@ -0,0 +1,37 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package comment
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// List 分页返回博客的评论，每个讨论串以树的形式返回.
+func (ctrl *CommentController) List(c *gin.Context) {
+	log.C(c).Infow("List comment function called")
+
+	var r v1.ListCommentRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Comments().List(c, c.GetString(known.XUsernameKey), c.Param("postID"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
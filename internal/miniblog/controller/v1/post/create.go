@@ -0,0 +1,56 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// Create 创建一条博客.
+func (ctrl *PostController) Create(c *gin.Context) {
+	log.C(c).Infow("Create post function called")
+
+	var r v1.CreatePostRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	username := c.GetString(known.XUsernameKey)
+	resp, err := ctrl.b.Posts().Create(c, username, &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// 只有博客的所有者才能修改和删除博客，添加授权策略失败时删除刚创建的博客，避免留下所有者无法管理的博客
+	if _, err := ctrl.a.AddNamedPolicies("p", ownerPolicies(username, resp.PostID)); err != nil {
+		log.C(c).Errorw("Failed to add post policies", "postID", resp.PostID, "err", err)
+		if err := ctrl.b.Posts().Delete(c, username, resp.PostID); err != nil {
+			log.C(c).Errorw("Failed to delete post without policies", "postID", resp.PostID, "err", err)
+		}
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// Delete 删除指定的博客.
+func (ctrl *PostController) Delete(c *gin.Context) {
+	log.C(c).Infow("Delete post function called")
+
+	username, postID := c.GetString(known.XUsernameKey), c.Param("postID")
+	if err := ctrl.b.Posts().Delete(c, username, postID); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := ctrl.removePolicies(username, postID); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// removePolicies 删除博客所有者对该博客的授权策略.
+func (ctrl *PostController) removePolicies(username, postID string) error {
+	_, err := ctrl.a.RemoveNamedPolicies("p", ownerPolicies(username, postID))
+
+	return err
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// DeleteCollection 批量删除当前用户的博客.
+func (ctrl *PostController) DeleteCollection(c *gin.Context) {
+	log.C(c).Infow("Batch delete post function called")
+
+	username, postIDs := c.GetString(known.XUsernameKey), c.QueryArray("postID")
+	if err := ctrl.b.Posts().DeleteCollection(c, username, postIDs); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	for _, postID := range postIDs {
+		if err := ctrl.removePolicies(username, postID); err != nil {
+			core.WriteResponse(c, err, nil)
+
+			return
+		}
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// List 返回博客列表，支持按作者、标签、创建时间和标题前缀过滤.
+func (ctrl *PostController) List(c *gin.Context) {
+	log.C(c).Infow("List post function called")
+
+	ctrl.list(c, "")
+}
+
+// ListByUser 返回指定用户的博客列表.
+func (ctrl *PostController) ListByUser(c *gin.Context) {
+	log.C(c).Infow("List user post function called")
+
+	ctrl.list(c, c.Param("name"))
+}
+
+// list 绑定列表查询参数并返回博客列表，author 不为空时覆盖查询参数中的作者.
+func (ctrl *PostController) list(c *gin.Context, author string) {
+	var r v1.ListPostRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	if author != "" {
+		r.Author = author
+	}
+
+	resp, err := ctrl.b.Posts().List(c, c.GetString(known.XUsernameKey), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
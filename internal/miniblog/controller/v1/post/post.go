@@ -0,0 +1,30 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/pkg/auth"
+)
+
+// PostController 是 post 模块在 Controller 层的实现，用来处理博客模块的请求.
+type PostController struct {
+	a *auth.Authz
+	b biz.IBiz
+}
+
+// New 创建一个 post controller.
+func New(ds store.IStore, a *auth.Authz) *PostController {
+	return &PostController{a: a, b: biz.NewBiz(ds)}
+}
+
+// ownerPolicies 返回博客所有者对该博客拥有的授权策略.
+func ownerPolicies(username, postID string) [][]string {
+	return [][]string{
+		{username, "/v1/posts/" + postID, "(PUT)|(DELETE)"},
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// AddReaction 回应博客，例如点赞.
+func (ctrl *PostController) AddReaction(c *gin.Context) {
+	log.C(c).Infow("Add post reaction function called")
+
+	err := ctrl.b.Posts().AddReaction(c, c.GetString(known.XUsernameKey), c.Param("postID"), c.Param("kind"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// RemoveReaction 取消对博客的回应.
+func (ctrl *PostController) RemoveReaction(c *gin.Context) {
+	log.C(c).Infow("Remove post reaction function called")
+
+	err := ctrl.b.Posts().RemoveReaction(c, c.GetString(known.XUsernameKey), c.Param("postID"), c.Param("kind"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
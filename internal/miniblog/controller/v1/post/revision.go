@@ -0,0 +1,109 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package post
+
+import (
+	"strconv"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// ListRevisions 返回博客的修订历史.
+func (ctrl *PostController) ListRevisions(c *gin.Context) {
+	log.C(c).Infow("List post revisions function called")
+
+	var r v1.ListPostRevisionRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Posts().ListRevisions(c, c.GetString(known.XUsernameKey), c.Param("postID"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// GetRevision 获取博客的指定修订.
+func (ctrl *PostController) GetRevision(c *gin.Context) {
+	log.C(c).Infow("Get post revision function called")
+
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage("revision must be an integer"), nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Posts().GetRevision(c, c.GetString(known.XUsernameKey), c.Param("postID"), revision)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// DiffRevisions 返回博客两个修订之间的 unified diff.
+func (ctrl *PostController) DiffRevisions(c *gin.Context) {
+	log.C(c).Infow("Diff post revisions function called")
+
+	var r v1.DiffPostRevisionRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Posts().DiffRevisions(c, c.GetString(known.XUsernameKey), c.Param("postID"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// RestoreRevision 将博客恢复到指定修订，恢复结果会保存为一条新的修订.
+func (ctrl *PostController) RestoreRevision(c *gin.Context) {
+	log.C(c).Infow("Restore post revision function called")
+
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage("revision must be an integer"), nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Posts().RestoreRevision(c, c.GetString(known.XUsernameKey), c.Param("postID"), revision)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
@@ -0,0 +1,21 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package tag
+
+import (
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+)
+
+// TagController 是 tag 模块在 Controller 层的实现，用来处理标签模块的请求.
+type TagController struct {
+	b biz.IBiz
+}
+
+// New 创建一个 tag controller.
+func New(ds store.IStore) *TagController {
+	return &TagController{b: biz.NewBiz(ds)}
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package upload
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// multipartOverhead 是 multipart 请求中除文件内容之外允许的最大字节数.
+const multipartOverhead = 1 << 20
+
+// Create 上传一个文件，文件通过 multipart 表单的 file 字段提交.
+func (ctrl *UploadController) Create(c *gin.Context) {
+	log.C(c).Infow("Create upload function called")
+
+	// 在解析表单之前限制请求体的大小，避免超大的请求占用磁盘
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.opts.MaxSize+multipartOverhead)
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			core.WriteResponse(c, errno.ErrUploadTooLarge, nil)
+
+			return
+		}
+
+		core.WriteResponse(c, errno.ErrUploadFileMissing, nil)
+
+		return
+	}
+
+	if fh.Size > ctrl.opts.MaxSize {
+		core.WriteResponse(c, errno.ErrUploadTooLarge, nil)
+
+		return
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+	defer f.Close()
+
+	resp, err := ctrl.b.Uploads().Create(c, c.GetString(known.XUsernameKey), fh.Filename, f, ctrl.opts)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package upload
+
+import (
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// mediaCacheControl 允许客户端永久缓存文件，文件以内容摘要命名，内容不会改变.
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+// Media 返回上传的文件，不需要登录.
+// 路径参数为 `<hash>` 时返回原文件，为 `<hash>-<width>` 时返回对应宽度的缩略图.
+func (ctrl *UploadController) Media(c *gin.Context) {
+	log.C(c).Infow("Get media function called")
+
+	hash, width, ok := parseMediaKey(c.Param("hash"))
+	if !ok {
+		core.WriteResponse(c, errno.ErrMediaNotFound, nil)
+
+		return
+	}
+
+	media, err := ctrl.b.Uploads().Open(c, hash, width)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+	defer media.Content.Close()
+
+	// 图片可以在页面中直接显示，其它类型的文件作为附件下载，避免被浏览器当作页面渲染
+	disposition := "attachment"
+	if strings.HasPrefix(media.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	c.Header("Content-Type", media.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": media.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", mediaCacheControl)
+	c.Header("ETag", `"`+media.Hash+`"`)
+
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, media.Content)
+}
+
+// parseMediaKey 解析 `<hash>` 或者 `<hash>-<width>` 格式的路径参数.
+func parseMediaKey(key string) (hash string, width int, ok bool) {
+	hash, w, found := strings.Cut(key, "-")
+	if b, err := hex.DecodeString(hash); err != nil || len(b) != 32 {
+		return "", 0, false
+	}
+
+	if found {
+		if width, err := strconv.Atoi(w); err == nil && width > 0 && strconv.Itoa(width) == w {
+			return hash, width, true
+		}
+
+		return "", 0, false
+	}
+
+	return hash, 0, true
+}
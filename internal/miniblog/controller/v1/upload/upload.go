@@ -0,0 +1,23 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package upload
+
+import (
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	uploadbiz "github.com/ischeng28/miniblog/internal/miniblog/biz/upload"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+)
+
+// UploadController 是 upload 模块在 Controller 层的实现，用来处理文件上传和访问请求.
+type UploadController struct {
+	b    biz.IBiz
+	opts *uploadbiz.Options
+}
+
+// New 创建一个 upload controller.
+func New(ds store.IStore, opts *uploadbiz.Options) *UploadController {
+	return &UploadController{b: biz.NewBiz(ds), opts: opts}
+}
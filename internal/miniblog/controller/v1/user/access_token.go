@@ -0,0 +1,73 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/token"
+)
+
+// CreateAccessToken 创建个人访问令牌，令牌只在创建时返回一次.
+func (ctrl *UserController) CreateAccessToken(c *gin.Context) {
+	log.C(c).Infow("Create access token function called")
+
+	var r v1.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	claims, _ := c.Value(known.XClaimsKey).(*token.Claims)
+	resp, err := ctrl.b.Users().CreateAccessToken(c, claims, c.Param("name"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// ListAccessTokens 返回用户的个人访问令牌列表.
+func (ctrl *UserController) ListAccessTokens(c *gin.Context) {
+	log.C(c).Infow("List access tokens function called")
+
+	resp, err := ctrl.b.Users().ListAccessTokens(c, c.GetString(known.XUsernameKey), c.Param("name"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// DeleteAccessToken 删除个人访问令牌.
+func (ctrl *UserController) DeleteAccessToken(c *gin.Context) {
+	log.C(c).Infow("Delete access token function called")
+
+	if err := ctrl.b.Users().DeleteAccessToken(c, c.GetString(known.XUsernameKey), c.Param("name"), c.Param("tokenID")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// Follow 关注指定的用户.
+func (ctrl *UserController) Follow(c *gin.Context) {
+	log.C(c).Infow("Follow user function called")
+
+	if err := ctrl.b.Users().Follow(c, c.GetString(known.XUsernameKey), c.Param("name")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// Unfollow 取消关注指定的用户.
+func (ctrl *UserController) Unfollow(c *gin.Context) {
+	log.C(c).Infow("Unfollow user function called")
+
+	if err := ctrl.b.Users().Unfollow(c, c.GetString(known.XUsernameKey), c.Param("name")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// ListFollowers 返回关注了指定用户的用户列表.
+func (ctrl *UserController) ListFollowers(c *gin.Context) {
+	log.C(c).Infow("List followers function called")
+
+	var r v1.ListFollowRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Users().ListFollowers(c, c.Param("name"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// ListFollowing 返回指定用户关注的用户列表.
+func (ctrl *UserController) ListFollowing(c *gin.Context) {
+	log.C(c).Infow("List following function called")
+
+	var r v1.ListFollowRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Users().ListFollowing(c, c.Param("name"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// ApproveFollower 批准指定用户的关注，批准的关注者可以看到仅关注者可见的博客.
+func (ctrl *UserController) ApproveFollower(c *gin.Context) {
+	log.C(c).Infow("Approve follower function called")
+
+	if err := ctrl.b.Users().ApproveFollower(c, c.GetString(known.XUsernameKey), c.Param("name"), c.Param("follower"), true); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// RevokeFollower 撤销对指定用户关注的批准.
+func (ctrl *UserController) RevokeFollower(c *gin.Context) {
+	log.C(c).Infow("Revoke follower function called")
+
+	if err := ctrl.b.Users().ApproveFollower(c, c.GetString(known.XUsernameKey), c.Param("name"), c.Param("follower"), false); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -21,7 +21,7 @@ func (ctrl *UserController) Login(c *gin.Context) {
 		core.WriteResponse(c, errno.ErrBind, nil)
 		return
 	}
-	resp, err := ctrl.b.Users().Login(c, &r)
+	resp, err := ctrl.b.Users().Login(c, &r, ctrl.opts)
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 		return
@@ -0,0 +1,138 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package user
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+)
+
+// EnrollTOTP 开始绑定身份验证器，返回密钥和 otpauth URI.
+func (ctrl *UserController) EnrollTOTP(c *gin.Context) {
+	log.C(c).Infow("Enroll totp function called")
+
+	resp, err := ctrl.b.Users().EnrollTOTP(c, c.GetString(known.XUsernameKey), c.Param("name"), ctrl.opts)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// VerifyTOTP 验证身份验证器生成的一次性密码并启用两步验证，返回恢复码.
+func (ctrl *UserController) VerifyTOTP(c *gin.Context) {
+	log.C(c).Infow("Verify totp function called")
+
+	var r v1.VerifyTOTPRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Users().VerifyTOTP(c, c.GetString(known.XUsernameKey), c.Param("name"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// DisableTOTP 关闭两步验证.
+func (ctrl *UserController) DisableTOTP(c *gin.Context) {
+	log.C(c).Infow("Disable totp function called")
+
+	var r v1.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	if err := ctrl.b.Users().DisableTOTP(c, c.GetString(known.XUsernameKey), c.Param("name"), &r); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部失效.
+func (ctrl *UserController) RegenerateRecoveryCodes(c *gin.Context) {
+	log.C(c).Infow("Regenerate recovery codes function called")
+
+	var r v1.RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Users().RegenerateRecoveryCodes(c, c.GetString(known.XUsernameKey), c.Param("name"), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
+
+// LoginTwoFactor 使用登录返回的挑战令牌和一次性密码换取 JWT Token 和刷新令牌.
+func (ctrl *UserController) LoginTwoFactor(c *gin.Context) {
+	log.C(c).Infow("Login two-factor function called")
+
+	var r v1.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errno.ErrBind, nil)
+
+		return
+	}
+
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		core.WriteResponse(c, errno.ErrInvalidParameter.SetMessage(err.Error()), nil)
+
+		return
+	}
+
+	resp, err := ctrl.b.Users().LoginTwoFactor(c, &r, ctrl.opts)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, resp)
+}
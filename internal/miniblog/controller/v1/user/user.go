@@ -7,17 +7,19 @@ package user
 
 import (
 	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	userbiz "github.com/ischeng28/miniblog/internal/miniblog/biz/user"
 	"github.com/ischeng28/miniblog/internal/miniblog/store"
 	"github.com/ischeng28/miniblog/pkg/auth"
 )
 
 // UserController 是 user 模块在 Controller 层的实现，用来处理用户模块的请求.
 type UserController struct {
-	a *auth.Authz
-	b biz.IBiz
+	a    *auth.Authz
+	b    biz.IBiz
+	opts *userbiz.AuthOptions
 }
 
 // New 创建一个 user controller.
-func New(ds store.IStore, a *auth.Authz) *UserController {
-	return &UserController{a: a, b: biz.NewBiz(ds)}
+func New(ds store.IStore, a *auth.Authz, opts *userbiz.AuthOptions) *UserController {
+	return &UserController{a: a, b: biz.NewBiz(ds), opts: opts}
 }
@@ -0,0 +1,180 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/markdown"
+	"github.com/ischeng28/miniblog/pkg/util/slug"
+)
+
+// Index 显示全站最近发布的博客.
+func (ctrl *WebController) Index(c *gin.Context) {
+	log.C(c).Infow("Get index page function called")
+
+	v := ctrl.newView("/")
+	if !ctrl.list(c, v, &v1.ListPostRequest{}) {
+		return
+	}
+
+	ctrl.render(c, http.StatusOK, "index", v)
+}
+
+// Blog 显示指定用户的博客首页.
+func (ctrl *WebController) Blog(c *gin.Context) {
+	log.C(c).Infow("Get blog page function called")
+
+	user, err := ctrl.b.Users().Get(c, c.Param("name"))
+	if err != nil {
+		ctrl.renderError(c, err)
+
+		return
+	}
+
+	v := ctrl.newView(known.BlogPath(user.Username))
+	v.User = user
+	v.Title = user.Nickname + " - " + ctrl.opts.Title
+	v.Description = user.Nickname + " 的博客"
+	v.FeedAtom = ctrl.absURL(known.BlogPath(user.Username) + "/feed.atom")
+	v.FeedRSS = ctrl.absURL(known.BlogPath(user.Username) + "/feed.rss")
+	if !ctrl.list(c, v, &v1.ListPostRequest{Author: user.Username}) {
+		return
+	}
+
+	ctrl.render(c, http.StatusOK, "blog", v)
+}
+
+// Post 显示博客页面，只有已发布的公开博客和 unlisted 博客可以访问.
+func (ctrl *WebController) Post(c *gin.Context) {
+	log.C(c).Infow("Get post page function called")
+
+	post, err := ctrl.b.Posts().Get(c, "", c.Param("postID"))
+	if err != nil {
+		ctrl.renderError(c, err)
+
+		return
+	}
+
+	// 路径中的用户名与作者不一致时跳转到规范地址
+	path := known.PostPath(post.Username, post.PostID)
+	if post.Username != c.Param("name") {
+		c.Redirect(http.StatusMovedPermanently, path)
+
+		return
+	}
+
+	summary, err := markdown.Summary(post.Content, summaryLength)
+	if err != nil {
+		ctrl.renderError(c, err)
+
+		return
+	}
+
+	v := ctrl.newView(path)
+	v.Post = post
+	v.Content = template.HTML(post.ContentHTML) // ContentHTML 已经过安全过滤
+	v.Title = post.Title + " - " + ctrl.opts.Title
+	v.Description = summary
+	v.OGType = "article"
+	v.Author = post.Username
+	v.PublishedTime = isoTime(post.PublishedAt)
+	v.NoIndex = post.Visibility == model.PostVisibilityUnlisted
+	v.FeedAtom = ctrl.absURL(known.BlogPath(post.Username) + "/feed.atom")
+	v.FeedRSS = ctrl.absURL(known.BlogPath(post.Username) + "/feed.rss")
+
+	ctrl.render(c, http.StatusOK, "post", v)
+}
+
+// Tag 显示带有指定标签的博客.
+func (ctrl *WebController) Tag(c *gin.Context) {
+	log.C(c).Infow("Get tag page function called")
+
+	// 标签名不区分大小写，统一跳转到 slug 对应的规范地址
+	s := slug.Make(c.Param("slug"))
+	if s == "" {
+		ctrl.renderError(c, errno.ErrPageNotFound)
+
+		return
+	}
+	if s != c.Param("slug") {
+		c.Redirect(http.StatusMovedPermanently, known.TagPath(s))
+
+		return
+	}
+
+	v := ctrl.newView(known.TagPath(s))
+	v.Tag = s
+	if !ctrl.list(c, v, &v1.ListPostRequest{Tag: s}) {
+		return
+	}
+
+	// 使用标签第一次被使用时的显示名，所有博客中同一个标签的显示名相同，找到第一个即可
+names:
+	for _, post := range v.Posts {
+		for _, tag := range post.Tags {
+			if tag.Slug == s {
+				v.Tag = tag.Name
+
+				break names
+			}
+		}
+	}
+	v.Title = "#" + v.Tag + " - " + ctrl.opts.Title
+	v.Description = "标签 " + v.Tag + " 下的博客"
+
+	ctrl.render(c, http.StatusOK, "tag", v)
+}
+
+// StyleSheet 返回代码高亮使用的 CSS.
+func (ctrl *WebController) StyleSheet(c *gin.Context) {
+	css, err := markdown.StyleSheet()
+	if err != nil {
+		c.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+
+		return
+	}
+
+	c.Header("Cache-Control", cacheControl)
+	c.Data(http.StatusOK, "text/css; charset=utf-8", []byte(css))
+}
+
+// list 查询已发布的博客并填充到页面数据中，失败时渲染错误页面并返回 false.
+// 分页使用请求中的 cursor 参数，带游标的页面使用包含游标的规范地址.
+func (ctrl *WebController) list(c *gin.Context, v *view, r *v1.ListPostRequest) bool {
+	r.Cursor, r.Limit, r.Status = c.Query("cursor"), pageSize, model.PostStatusPublished
+
+	resp, err := ctrl.b.Posts().List(c, "", r)
+	if err != nil {
+		ctrl.renderError(c, err)
+
+		return false
+	}
+
+	if v.Posts, err = items(resp.Posts); err != nil {
+		ctrl.renderError(c, err)
+
+		return false
+	}
+
+	if r.Cursor != "" {
+		v.Canonical += "?cursor=" + url.QueryEscape(r.Cursor)
+	}
+	if resp.NextCursor != "" {
+		v.NextURL = c.Request.URL.Path + "?cursor=" + url.QueryEscape(resp.NextCursor)
+	}
+
+	return true
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package web
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	v1 "github.com/ischeng28/miniblog/pkg/api/miniblog/v1"
+	"github.com/ischeng28/miniblog/pkg/markdown"
+)
+
+const (
+	// pageSize 是列表页每页显示的博客数.
+	pageSize = 20
+	// summaryLength 是博客摘要的最大字符数.
+	summaryLength = 200
+	// cacheControl 允许浏览器和代理缓存页面 1 分钟，页面只包含公开内容，与登录状态无关.
+	cacheControl = "public, max-age=60"
+)
+
+// pages 是主题目录中除 layout.html 之外必须提供的页面模板.
+var pages = []string{"index", "blog", "post", "tag", "error"}
+
+// Options 包含公开页面的站点信息和模板目录.
+type Options struct {
+	// Title 是站点名称.
+	Title string
+	// URL 是站点对外访问的根地址，用来生成 canonical 链接和 OpenGraph 标签.
+	URL string
+	// ThemeDir 是主题目录，包含 layout.html、各个页面的模板以及 static 静态文件目录.
+	ThemeDir string
+}
+
+// WebController 是公开 HTML 页面在 Controller 层的实现，所有页面只读并且不需要登录.
+type WebController struct {
+	b     biz.IBiz
+	opts  *Options
+	pages map[string]*template.Template
+}
+
+// New 创建一个 web controller，并加载主题目录中的模板.
+func New(ds store.IStore, opts *Options) (*WebController, error) {
+	funcs := template.FuncMap{
+		"blogPath": known.BlogPath,
+		"postPath": known.PostPath,
+		"tagPath":  known.TagPath,
+	}
+
+	// 每个页面模板与 layout.html 组成一个独立的模板集合，页面通过定义 content 模板填充布局
+	ctrl := &WebController{b: biz.NewBiz(ds), opts: opts, pages: make(map[string]*template.Template, len(pages))}
+	for _, name := range pages {
+		t, err := template.New("layout.html").Funcs(funcs).ParseFiles(
+			filepath.Join(opts.ThemeDir, "layout.html"),
+			filepath.Join(opts.ThemeDir, name+".html"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		ctrl.pages[name] = t
+	}
+
+	return ctrl, nil
+}
+
+// view 是传给页面模板的数据，布局中使用的字段对所有页面都有效.
+type view struct {
+	SiteTitle string
+	// Title 和 Description 同时用于 <title>、description 和 OpenGraph/Twitter 标签.
+	Title       string
+	Description string
+	// Canonical 是页面的规范链接.
+	Canonical string
+	// OGType 是 OpenGraph 的页面类型：website 或 article.
+	OGType        string
+	Author        string
+	PublishedTime string
+	// NoIndex 表示页面不应该被搜索引擎收录，用于只能通过链接访问的博客.
+	NoIndex bool
+	// FeedAtom 和 FeedRSS 是页面对应的订阅源地址，用于订阅阅读器自动发现.
+	FeedAtom string
+	FeedRSS  string
+
+	User    *v1.GetUserResponse
+	Post    *v1.GetPostResponse
+	Content template.HTML
+	Posts   []*postItem
+	Tag     string
+	// NextURL 是下一页的地址，为空表示没有更多数据.
+	NextURL string
+	Message string
+}
+
+// postItem 是列表页中的一篇博客.
+type postItem struct {
+	*v1.PostInfo
+	Summary string
+}
+
+// newView 创建包含站点信息的页面数据，path 是页面的路径.
+func (ctrl *WebController) newView(path string) *view {
+	return &view{
+		SiteTitle: ctrl.opts.Title,
+		Title:     ctrl.opts.Title,
+		Canonical: ctrl.absURL(path),
+		OGType:    "website",
+		FeedAtom:  ctrl.absURL("/feed.atom"),
+		FeedRSS:   ctrl.absURL("/feed.rss"),
+	}
+}
+
+// absURL 返回 path 对应的绝对地址.
+func (ctrl *WebController) absURL(path string) string {
+	return strings.TrimRight(ctrl.opts.URL, "/") + path
+}
+
+// render 渲染页面模板并返回.
+func (ctrl *WebController) render(c *gin.Context, status int, page string, v *view) {
+	var buf bytes.Buffer
+	if err := ctrl.pages[page].Execute(&buf, v); err != nil {
+		log.C(c).Errorw("Failed to render page", "page", page, "err", err)
+		c.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+
+		return
+	}
+
+	if status == http.StatusOK {
+		c.Header("Cache-Control", cacheControl)
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// renderError 根据错误码渲染错误页面.
+func (ctrl *WebController) renderError(c *gin.Context, err error) {
+	status, _, message := errno.Decode(err)
+
+	v := ctrl.newView(c.Request.URL.Path)
+	v.Title = http.StatusText(status) + " - " + ctrl.opts.Title
+	v.Message = message
+	ctrl.render(c, status, "error", v)
+}
+
+// items 将博客列表转换为列表页中显示的博客.
+func items(posts []*v1.PostInfo) ([]*postItem, error) {
+	ret := make([]*postItem, 0, len(posts))
+	for _, post := range posts {
+		summary, err := markdown.Summary(post.Content, summaryLength)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &postItem{PostInfo: post, Summary: summary})
+	}
+
+	return ret, nil
+}
+
+// isoTime 将接口返回的本地时间字符串转换为 ISO 8601 格式，用于 article:published_time 等标签.
+func isoTime(s string) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
+	if err != nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package wellknown 实现了 RFC 8615 定义的 `/.well-known/` 下的接口.
+package wellknown
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/pkg/token"
+)
+
+// MaxAge 是允许下游服务缓存公钥的时间，遇到未知的 kid 时应该重新获取.
+const MaxAge = 5 * time.Minute
+
+// cacheControl 是 JWKS 响应的 Cache-Control 头.
+var cacheControl = fmt.Sprintf("public, max-age=%d", int(MaxAge/time.Second))
+
+// JWKS 返回验证 Token 所用的公钥，下游服务可以使用这些公钥离线验证 miniblog 签发的 Token.
+func JWKS(c *gin.Context) {
+	log.C(c).Infow("Get jwks function called")
+
+	c.Header("Cache-Control", cacheControl)
+	c.JSON(http.StatusOK, token.PublicKeys())
+}
@@ -6,15 +6,26 @@
 package miniblog
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-
+	"time"
+
+	feedbiz "github.com/ischeng28/miniblog/internal/miniblog/biz/feed"
+	"github.com/ischeng28/miniblog/internal/miniblog/biz/post"
+	uploadbiz "github.com/ischeng28/miniblog/internal/miniblog/biz/upload"
+	userbiz "github.com/ischeng28/miniblog/internal/miniblog/biz/user"
+	"github.com/ischeng28/miniblog/internal/miniblog/blob"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/web"
+	"github.com/ischeng28/miniblog/internal/miniblog/search"
 	"github.com/ischeng28/miniblog/internal/miniblog/store"
 	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/pkg/token"
 	"github.com/marmotedu/miniblog/pkg/db"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gorm.io/gorm"
 )
 
 const (
@@ -22,6 +33,14 @@ const (
 	recommendedHomeDir = "workspace/miniblog/configs"
 	//defaultConfigName 指定了miniblog服务的默认配置文件名.
 	defaultConfigName = "miniblog"
+	// defaultRefreshTokenTTL 是未配置 auth.refresh-token-ttl 时刷新令牌的有效期.
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// defaultTokenExpiry 是未配置 jwt.expiry 时 Token 的有效期.
+	defaultTokenExpiry = 30 * time.Minute
+	// defaultChallengeTTL 是未配置 auth.challenge-ttl 时两步验证挑战令牌的有效期.
+	defaultChallengeTTL = 5 * time.Minute
+	// defaultTOTPIssuer 是未配置 site.title 时身份验证器应用中显示的服务名称.
+	defaultTOTPIssuer = "miniblog"
 )
 
 func initConfig() {
@@ -78,6 +97,123 @@ func logOptions() *log.Options {
 
 // initStore 读取 db 配置，创建 gorm.DB 实例，并初始化 miniblog store 层.
 func initStore() error {
+	ins, err := newDB()
+	if err != nil {
+		return err
+	}
+
+	_ = store.NewStore(ins)
+
+	return nil
+}
+
+// initSearch 读取 search 配置，打开博客全文索引.
+func initSearch() (*search.Index, error) {
+	return search.NewIndex(viper.GetString("search.index-path"))
+}
+
+// initBlob 读取 blob 配置，创建保存上传文件的 BlobStore.
+func initBlob() error {
+	switch driver := viper.GetString("blob.driver"); driver {
+	case "fs":
+		s, err := blob.NewFSStore(viper.GetString("blob.fs.dir"))
+		if err != nil {
+			return err
+		}
+		blob.Init(s)
+	default:
+		return fmt.Errorf("unsupported blob driver: %q", driver)
+	}
+
+	return nil
+}
+
+// tokenOptions 从 viper 中读取 JWT 配置，构建 `*token.Options` 并返回.
+func tokenOptions() *token.Options {
+	opts := &token.Options{
+		Secret:   viper.GetString("jwt-secret"),
+		Issuer:   viper.GetString("jwt.issuer"),
+		Audience: viper.GetStringSlice("jwt.audience"),
+		Expiry:   viper.GetDuration("jwt.expiry"),
+		Leeway:   viper.GetDuration("jwt.leeway"),
+	}
+	if opts.Expiry <= 0 {
+		opts.Expiry = defaultTokenExpiry
+	}
+
+	return opts
+}
+
+// authOptions 从 viper 中读取签发令牌的配置，构建 `*userbiz.AuthOptions` 并返回.
+func authOptions() *userbiz.AuthOptions {
+	opts := &userbiz.AuthOptions{
+		RefreshTokenTTL: viper.GetDuration("auth.refresh-token-ttl"),
+		ChallengeTTL:    viper.GetDuration("auth.challenge-ttl"),
+		TOTPIssuer:      viper.GetString("site.title"),
+	}
+	if opts.RefreshTokenTTL <= 0 {
+		opts.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+	if opts.ChallengeTTL <= 0 {
+		opts.ChallengeTTL = defaultChallengeTTL
+	}
+	if opts.TOTPIssuer == "" {
+		opts.TOTPIssuer = defaultTOTPIssuer
+	}
+
+	return opts
+}
+
+// uploadOptions 从 viper 中读取上传文件的限制，构建 `*uploadbiz.Options` 并返回.
+func uploadOptions() *uploadbiz.Options {
+	return &uploadbiz.Options{
+		MaxSize:         viper.GetInt64("upload.max-size"),
+		Quota:           viper.GetInt64("upload.quota"),
+		ContentTypes:    viper.GetStringSlice("upload.content-types"),
+		ThumbnailWidths: viper.GetIntSlice("upload.thumbnail-widths"),
+	}
+}
+
+// trendingOptions 从 viper 中读取热门博客排行的配置，构建 `*post.TrendingOptions` 并返回.
+func trendingOptions() *post.TrendingOptions {
+	opts := &post.TrendingOptions{
+		Window:   viper.GetDuration("trending.window"),
+		HalfLife: viper.GetDuration("trending.half-life"),
+		Size:     viper.GetInt("trending.size"),
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultTrendingWindow
+	}
+	if opts.HalfLife <= 0 {
+		opts.HalfLife = defaultTrendingHalfLife
+	}
+	if opts.Size <= 0 {
+		opts.Size = defaultTrendingSize
+	}
+
+	return opts
+}
+
+// feedOptions 从 viper 中读取站点和订阅源配置，构建 `*feedbiz.Options` 并返回.
+func feedOptions() *feedbiz.Options {
+	return &feedbiz.Options{
+		Title: viper.GetString("site.title"),
+		URL:   viper.GetString("site.url"),
+		Size:  viper.GetInt("feed.size"),
+	}
+}
+
+// webOptions 从 viper 中读取站点和主题配置，构建 `*web.Options` 并返回.
+func webOptions() *web.Options {
+	return &web.Options{
+		Title:    viper.GetString("site.title"),
+		URL:      viper.GetString("site.url"),
+		ThemeDir: viper.GetString("web.theme-dir"),
+	}
+}
+
+// newDB 读取 db 配置，创建 gorm.DB 实例.
+func newDB() (*gorm.DB, error) {
 	dbOptions := &db.MySQLOptions{
 		Host:                  viper.GetString("db.host"),
 		Username:              viper.GetString("db.username"),
@@ -89,12 +225,5 @@ func initStore() error {
 		LogLevel:              viper.GetInt("db.log-level"),
 	}
 
-	ins, err := db.NewMySQL(dbOptions)
-	if err != nil {
-		return err
-	}
-
-	_ = store.NewStore(ins)
-
-	return nil
+	return db.NewMySQL(dbOptions)
 }
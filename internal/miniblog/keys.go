@@ -0,0 +1,114 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/wellknown"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+	"github.com/ischeng28/miniblog/pkg/token"
+)
+
+const (
+	// keyCheckInterval 是重新加载密钥目录和检查是否需要轮换密钥的间隔.
+	keyCheckInterval = time.Minute
+	// defaultKeyRetention 是未配置 jwt.key-retention 时旧密钥被替换之后继续保留的时间.
+	defaultKeyRetention = 24 * time.Hour
+	// keyActivation 是新密钥生成之后开始签发 Token 的延迟，保证下游服务缓存的 JWKS 和其它实例加载的密钥都已经包含新密钥.
+	keyActivation = wellknown.MaxAge + keyCheckInterval
+)
+
+// initKeys 读取 jwt 配置，使用非对称签名算法时加载密钥目录并启动密钥轮换任务，ctx 被取消时退出.
+// 密钥目录为空或者最新密钥的算法与配置不一致时立即生成新的密钥.
+// jwt.key-retention 小于 Token 的有效期加上允许的时钟偏差时返回错误，否则旧密钥签发的 Token 会在过期之前无法验证.
+func initKeys(ctx context.Context) error {
+	algorithm := viper.GetString("jwt.algorithm")
+	switch algorithm {
+	case "", "HS256":
+		return nil
+	case token.AlgorithmRS256, token.AlgorithmEdDSA:
+	default:
+		return fmt.Errorf("unsupported jwt algorithm: %q", algorithm)
+	}
+
+	interval := viper.GetDuration("jwt.rotation-interval")
+	retention := viper.GetDuration("jwt.key-retention")
+	if retention <= 0 {
+		retention = defaultKeyRetention
+	}
+	if opts := tokenOptions(); retention < opts.Expiry+opts.Leeway {
+		return fmt.Errorf("jwt.key-retention %s is shorter than jwt.expiry %s plus jwt.leeway %s", retention, opts.Expiry, opts.Leeway)
+	}
+
+	keys, err := token.LoadKeySet(viper.GetString("jwt.key-dir"))
+	if err != nil {
+		return err
+	}
+	keys.SetActivation(keyActivation)
+
+	// 检查包括还没有激活的最新密钥，避免重启时重复生成密钥
+	if latest := keys.Latest(); latest == nil || latest.Algorithm != algorithm {
+		key, err := keys.Rotate(algorithm)
+		if err != nil {
+			return err
+		}
+		log.Infow("Generated jwt signing key", "kid", key.ID, "algorithm", key.Algorithm)
+	}
+
+	token.UseKeySet(keys)
+
+	log.Infow("Start jwt key rotation", "algorithm", algorithm, "kid", keys.Current().ID, "interval", interval, "retention", retention, "activation", keyActivation)
+
+	go func() {
+		ticker := time.NewTicker(keyCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			rotateKeys(keys, algorithm, interval, retention)
+		}
+	}()
+
+	return nil
+}
+
+// rotateKeys 重新加载密钥目录，最新密钥超过 interval 时生成新的密钥，并删除被替换超过 retention 的旧密钥.
+// 新密钥先通过 JWKS 公开，keyActivation 之后才用来签发 Token. interval 不大于 0 时不自动轮换.
+func rotateKeys(keys *token.KeySet, algorithm string, interval, retention time.Duration) {
+	// 多个实例共享密钥目录时，加载其它实例生成的密钥
+	if err := keys.Reload(); err != nil {
+		log.Errorw("Failed to reload jwt keys", "err", err)
+		return
+	}
+
+	if latest := keys.Latest(); interval > 0 && (latest == nil || time.Since(latest.CreatedAt) >= interval) {
+		key, err := keys.Rotate(algorithm)
+		if err != nil {
+			log.Errorw("Failed to rotate jwt signing key", "err", err)
+			return
+		}
+		log.Infow("Rotated jwt signing key", "kid", key.ID, "algorithm", key.Algorithm)
+	}
+
+	n, err := keys.Prune(retention)
+	if err != nil {
+		log.Errorw("Failed to prune jwt keys", "err", err)
+		return
+	}
+	if n > 0 {
+		log.Infow("Pruned retired jwt keys", "count", n)
+	}
+}
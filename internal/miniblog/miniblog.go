@@ -9,7 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/ischeng28/miniblog/internal/pkg/known"
+	"github.com/ischeng28/miniblog/pkg/markdown"
 	"github.com/ischeng28/miniblog/pkg/token"
 	"net/http"
 	"os"
@@ -77,6 +77,9 @@ Find more miniblog information at:
 	// 添加 --version 标志
 	verflag.AddFlags(cmd.PersistentFlags())
 
+	// 添加子命令
+	cmd.AddCommand(newReindexCommand())
+
 	return cmd
 }
 
@@ -87,8 +90,51 @@ func run() error {
 		return err
 	}
 
-	// 设置token包的签发密钥，用于token包的token签发和解析
-	token.Init(viper.GetString("jwt-secret"), known.XUsernameKey)
+	// 打开博客全文索引
+	idx, err := initSearch()
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	// 初始化上传文件的存储
+	if err := initBlob(); err != nil {
+		return err
+	}
+
+	// ctx 用来通知后台任务退出
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 启动定时发布任务
+	startPublisher(ctx)
+
+	// 启动过期挑战令牌的定期清理任务
+	startChallengeCleaner(ctx)
+
+	// 启动未被引用的上传文件的定期清理任务
+	startUploadCollector(ctx)
+
+	// 启动浏览次数的批量写入任务，服务器关闭后写入剩余的浏览次数
+	views := startViewCounter(ctx)
+	defer flushViews(views)
+
+	// 启动热门博客排行的定期计算任务
+	startTrending(ctx)
+
+	// 设置 Markdown 渲染结果的缓存大小
+	markdown.Init(viper.GetInt("markdown.cache-size"))
+
+	// 在后台构建相关博客索引，之后随着博客的修改增量更新
+	startRelated(ctx)
+
+	// 设置token包的签发密钥、有效期和校验规则，用于token包的token签发和解析
+	token.Init(tokenOptions())
+
+	// 使用非对称签名算法时加载签名密钥，并启动密钥轮换任务
+	if err := initKeys(ctx); err != nil {
+		return err
+	}
 
 	// 设置 Gin 模式
 	gin.SetMode(viper.GetString("runmode"))
@@ -97,7 +143,8 @@ func run() error {
 	g := gin.New()
 
 	// gin.Recovery() 中间件，用来捕获任何 panic，并恢复
-	mws := []gin.HandlerFunc{gin.Recovery(), mw.NoCache, mw.Cors, mw.Secure, mw.RequestID()}
+	// mw.NoCache 只用于 API 路由，订阅源、页面、静态文件、上传的文件和 JWKS 需要允许客户端缓存，见 installRouters
+	mws := []gin.HandlerFunc{gin.Recovery(), mw.Cors, mw.Secure, mw.RequestID()}
 
 	g.Use(mws...)
 
@@ -112,8 +159,7 @@ func run() error {
 	httpssrv := startSecureServer(g)
 
 	// 等待中断信号优雅地关闭服务器（10 秒超时)。
-	//quit := make(chan os.Signal, 1)
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	// kill 默认会发送 syscall.SIGTERM 信号
 	// kill -2 发送 syscall.SIGINT 信号，我们常用的 CTRL + C 就是触发系统 SIGINT 信号
 	// kill -9 发送 syscall.SIGKILL 信号，但是不能被捕获，所以不需要添加它
@@ -121,17 +167,20 @@ func run() error {
 	<-quit                                               // 阻塞在此，当接收到上述两种信号时才会往下执行
 	log.Infow("Shutting down server ...")
 
+	// 停止后台任务
+	cancel()
+
 	// 创建 ctx 用于通知服务器 goroutine, 它有 10 秒时间完成当前正在处理的请求
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
 
 	// 10 秒内优雅关闭服务（将未处理完的请求处理完再关闭服务），超过 10 秒就超时退出
-	if err := httpsrv.Shutdown(ctx); err != nil {
+	if err := httpsrv.Shutdown(shutdownCtx); err != nil {
 		log.Errorw("Insecure Server forced to shutdown", "err", err)
 		return err
 	}
 
-	if err := httpssrv.Shutdown(ctx); err != nil {
+	if err := httpssrv.Shutdown(shutdownCtx); err != nil {
 		log.Errorw("Secure Server forced to shutdown", "err", err)
 		return err
 	}
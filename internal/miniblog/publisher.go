@@ -0,0 +1,74 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+const (
+	// defaultPublishInterval 是未配置 scheduler.interval 时检查定时博客的间隔.
+	defaultPublishInterval = 10 * time.Second
+	// defaultPublishBatchSize 是未配置 scheduler.batch-size 时每次最多发布的博客数.
+	defaultPublishBatchSize = 100
+)
+
+// startPublisher 在后台定期发布到达发布时间的定时博客，ctx 被取消时退出.
+// 多个 miniblog 实例可以同时运行定时发布任务，每条博客只会被其中一个实例发布.
+func startPublisher(ctx context.Context) {
+	interval := viper.GetDuration("scheduler.interval")
+	if interval <= 0 {
+		interval = defaultPublishInterval
+	}
+
+	batchSize := viper.GetInt("scheduler.batch-size")
+	if batchSize <= 0 {
+		batchSize = defaultPublishBatchSize
+	}
+
+	// owner 唯一标识当前实例，用来在数据库中认领定时博客
+	hostname, _ := os.Hostname()
+	owner := hostname + "-" + uuid.New().String()[:8]
+
+	log.Infow("Start scheduled post publisher", "owner", owner, "interval", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// 每次发布一批，直到没有到期的定时博客为止
+			for {
+				n, err := biz.NewBiz(store.S).Posts().PublishScheduled(ctx, owner, batchSize)
+				if err != nil {
+					log.Errorw("Failed to publish scheduled posts", "err", err)
+					break
+				}
+				if n > 0 {
+					log.Infow("Published scheduled posts", "count", n)
+				}
+				if n < batchSize {
+					break
+				}
+			}
+		}
+	}()
+}
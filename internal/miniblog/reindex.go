@@ -0,0 +1,79 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/search"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// reindexBatchSize 是重建索引时每批从 MySQL 读取的博客数.
+const reindexBatchSize = 100
+
+// newReindexCommand 创建 `miniblog reindex` 子命令，用来从 MySQL 重建博客全文索引.
+func newReindexCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the post full-text search index from MySQL",
+		Long: `Rebuild the post full-text search index from MySQL.
+
+The index file is locked by a running miniblog server, so stop the server before reindexing.`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Init(logOptions())
+			defer log.Sync()
+
+			return reindex(cmd.Context())
+		},
+	}
+}
+
+// reindex 删除已有的索引，并按批次将 MySQL 中的所有博客写入新索引.
+func reindex(ctx context.Context) error {
+	if err := initStore(); err != nil {
+		return err
+	}
+
+	path := viper.GetString("search.index-path")
+	idx, err := search.Rebuild(path)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	page, _ := core.NewPage("", reindexBatchSize)
+	total := 0
+	for {
+		_, posts, next, err := store.S.Posts().List(ctx, &store.PostFilter{}, page)
+		if err != nil {
+			return err
+		}
+
+		if err := idx.Index(posts...); err != nil {
+			return err
+		}
+
+		total += len(posts)
+		log.Infow("Indexed posts", "count", total)
+
+		if next == "" {
+			break
+		}
+		page.Cursor, _ = core.DecodeCursor(next)
+	}
+
+	log.Infow("Rebuild search index completed", "path", path, "count", total)
+
+	return nil
+}
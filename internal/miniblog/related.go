@@ -0,0 +1,33 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"time"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/related"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+// startRelated 创建相关博客索引，并在后台将 MySQL 中所有已发布的博客写入索引.
+// 构建完成之前相关博客接口只返回已经写入索引的博客.
+func startRelated(ctx context.Context) {
+	related.NewIndex()
+
+	go func() {
+		start := time.Now()
+		count, err := biz.NewBiz(store.S).Posts().BuildRelated(ctx)
+		if err != nil {
+			log.Errorw("Failed to build related posts index", "count", count, "err", err)
+			return
+		}
+
+		log.Infow("Build related posts index completed", "count", count, "elapsed", time.Since(start))
+	}()
+}
@@ -0,0 +1,253 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package related 实现了基于 TF-IDF 余弦相似度和标签重合度的相关博客推荐.
+// 索引保存在内存中，启动时在后台从 MySQL 构建，之后随着博客的修改增量更新.
+package related
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+)
+
+// 相似度由正文相似度和标签相似度加权求和得到.
+const (
+	textWeight = 0.7
+	tagWeight  = 0.3
+	// titleBoost 是标题中的词相对于正文中的词的权重.
+	titleBoost = 2
+)
+
+var (
+	once sync.Once
+	// S 全局变量，方便其它包直接调用已初始化好的 S 实例.
+	S *Index
+)
+
+// Document 是写入索引的博客.
+type Document struct {
+	PostID string
+	Title  string
+	// Content 是博客的正文，Markdown 语法中的标点在分词时会被忽略.
+	Content string
+	// Tags 是博客标签的 slug.
+	Tags []string
+}
+
+// Hit 是一篇相关博客.
+type Hit struct {
+	PostID string
+	Score  float64
+}
+
+// document 是索引中的一篇博客.
+type document struct {
+	// tf 是每个词的词频权重.
+	tf map[string]float64
+	// norm 是写入索引时计算的 TF-IDF 向量长度.
+	norm float64
+	tags []string
+}
+
+// Index 是内存中的相关博客索引，可以被多个 goroutine 同时访问.
+type Index struct {
+	mu   sync.RWMutex
+	docs map[string]*document
+	// df 是包含每个词的博客数.
+	df map[string]int
+	// terms 和 tags 是倒排索引，用来找出与指定博客有相同词或者相同标签的博客.
+	terms map[string]map[string]float64
+	tags  map[string]map[string]struct{}
+}
+
+// NewIndex 创建一个空的索引并设置全局变量 S.
+func NewIndex() *Index {
+	once.Do(func() {
+		S = &Index{
+			docs:  make(map[string]*document),
+			df:    make(map[string]int),
+			terms: make(map[string]map[string]float64),
+			tags:  make(map[string]map[string]struct{}),
+		}
+	})
+
+	return S
+}
+
+// Add 将博客写入索引，博客已经在索引中时替换原来的内容.
+func (i *Index) Add(docs ...*Document) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, d := range docs {
+		i.remove(d.PostID)
+
+		counts := make(map[string]int)
+		for _, term := range tokenize(d.Title) {
+			counts[term] += titleBoost
+		}
+		for _, term := range tokenize(d.Content) {
+			counts[term]++
+		}
+
+		tags := slices.Clone(d.Tags)
+		slices.Sort(tags)
+		doc := &document{tf: make(map[string]float64, len(counts)), tags: slices.Compact(tags)}
+		for term, n := range counts {
+			// 对词频取对数，避免在正文中反复出现的词主导相似度
+			doc.tf[term] = 1 + math.Log(float64(n))
+			i.df[term]++
+			if i.terms[term] == nil {
+				i.terms[term] = make(map[string]float64)
+			}
+			i.terms[term][d.PostID] = doc.tf[term]
+		}
+		for _, tag := range doc.tags {
+			if i.tags[tag] == nil {
+				i.tags[tag] = make(map[string]struct{})
+			}
+			i.tags[tag][d.PostID] = struct{}{}
+		}
+
+		i.docs[d.PostID] = doc
+		doc.norm = i.norm(doc)
+	}
+}
+
+// Refresh 使用当前的 idf 重新计算所有博客的向量长度.
+// 新增和删除博客会改变 idf，已经在索引中的博客的向量长度不会随之更新，批量写入大量博客之后需要调用 Refresh.
+func (i *Index) Refresh() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, doc := range i.docs {
+		doc.norm = i.norm(doc)
+	}
+}
+
+// Delete 从索引中删除博客.
+func (i *Index) Delete(postIDs ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, postID := range postIDs {
+		i.remove(postID)
+	}
+}
+
+// Similar 返回与 postID 最相似的 n 篇博客，按相似度从高到低排列，不包含 postID 本身.
+// 候选博客的向量长度使用写入索引或者最近一次 Refresh 时计算的值.
+func (i *Index) Similar(postID string, n int) []Hit {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	doc := i.docs[postID]
+	if doc == nil {
+		return nil
+	}
+
+	// 通过倒排索引计算查询博客与每篇候选博客的向量点积
+	dots := make(map[string]float64)
+	var norm float64
+	for term, tf := range doc.tf {
+		idf := i.idf(term)
+		w := tf * idf
+		norm += w * w
+		for id, otherTF := range i.terms[term] {
+			if id != postID {
+				dots[id] += w * otherTF * idf
+			}
+		}
+	}
+	norm = math.Sqrt(norm)
+
+	shared := make(map[string]int)
+	for _, tag := range doc.tags {
+		for id := range i.tags[tag] {
+			if id != postID {
+				shared[id]++
+			}
+		}
+	}
+
+	scores := make(map[string]float64, len(dots)+len(shared))
+	for id, dot := range dots {
+		if otherNorm := i.docs[id].norm; norm > 0 && otherNorm > 0 {
+			scores[id] += textWeight * dot / (norm * otherNorm)
+		}
+	}
+	for id, n := range shared {
+		// 标签的 Jaccard 相似度
+		union := len(doc.tags) + len(i.docs[id].tags) - n
+		scores[id] += tagWeight * float64(n) / float64(union)
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			hits = append(hits, Hit{PostID: id, Score: score})
+		}
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.PostID, b.PostID)
+	})
+
+	return hits[:min(n, len(hits))]
+}
+
+// Len 返回索引中的博客数.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.docs)
+}
+
+// remove 从索引中删除博客，调用方需要持有写锁.
+func (i *Index) remove(postID string) {
+	doc := i.docs[postID]
+	if doc == nil {
+		return
+	}
+
+	for term := range doc.tf {
+		if i.df[term]--; i.df[term] == 0 {
+			delete(i.df, term)
+			delete(i.terms, term)
+		} else {
+			delete(i.terms[term], postID)
+		}
+	}
+	for _, tag := range doc.tags {
+		if delete(i.tags[tag], postID); len(i.tags[tag]) == 0 {
+			delete(i.tags, tag)
+		}
+	}
+
+	delete(i.docs, postID)
+}
+
+// idf 返回词的逆文档频率，调用方需要持有读锁.
+func (i *Index) idf(term string) float64 {
+	return math.Log(1 + float64(len(i.docs))/float64(i.df[term]))
+}
+
+// norm 返回博客 TF-IDF 向量的长度，调用方需要持有读锁.
+func (i *Index) norm(doc *document) float64 {
+	var sum float64
+	for term, tf := range doc.tf {
+		w := tf * i.idf(term)
+		sum += w * w
+	}
+
+	return math.Sqrt(sum)
+}
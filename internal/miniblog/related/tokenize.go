@@ -0,0 +1,69 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package related
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords 是不参与相似度计算的常见英文词.
+var stopWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be but by can do for from has have how if in into is it its
+		not of on or so that the their then there these this to was we were what when which will with you your`) {
+		stopWords[w] = struct{}{}
+	}
+}
+
+// tokenize 将文本切分为用于计算相似度的词.
+// 英文和数字按照非字母数字字符切分并转换为小写，中文没有分隔符，按照相邻两个汉字切分（bigram）.
+func tokenize(text string) []string {
+	var (
+		tokens []string
+		word   []rune
+		han    []rune
+	)
+
+	flushWord := func() {
+		w := string(word)
+		word = word[:0]
+		if len([]rune(w)) < 2 || strings.IndexFunc(w, unicode.IsLetter) < 0 {
+			return
+		}
+		if _, ok := stopWords[w]; !ok {
+			tokens = append(tokens, w)
+		}
+	}
+	flushHan := func() {
+		if len(han) == 1 {
+			tokens = append(tokens, string(han))
+		}
+		for j := 0; j+1 < len(han); j++ {
+			tokens = append(tokens, string(han[j:j+2]))
+		}
+		han = han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return tokens
+}
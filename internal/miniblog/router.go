@@ -6,11 +6,22 @@
 package miniblog
 
 import (
+	"path/filepath"
+
 	"github.com/gin-gonic/gin"
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/feed"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/v1/comment"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/v1/post"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/v1/tag"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/v1/upload"
 	"github.com/ischeng28/miniblog/internal/miniblog/controller/v1/user"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/web"
+	"github.com/ischeng28/miniblog/internal/miniblog/controller/wellknown"
 	"github.com/ischeng28/miniblog/internal/miniblog/store"
 	"github.com/ischeng28/miniblog/internal/pkg/core"
 	"github.com/ischeng28/miniblog/internal/pkg/errno"
+	"github.com/ischeng28/miniblog/internal/pkg/known"
 	"github.com/ischeng28/miniblog/internal/pkg/log"
 	mw "github.com/ischeng28/miniblog/internal/pkg/middleware"
 	"github.com/ischeng28/miniblog/pkg/auth"
@@ -19,12 +30,12 @@ import (
 // installRouters 安装 miniblog 接口路由.
 func installRouters(g *gin.Engine) error {
 	// 注册 404 Handler.
-	g.NoRoute(func(c *gin.Context) {
+	g.NoRoute(mw.NoCache, func(c *gin.Context) {
 		core.WriteResponse(c, errno.ErrPageNotFound, nil)
 	})
 
 	// 注册 /healthz handler.
-	g.GET("/healthz", func(c *gin.Context) {
+	g.GET("/healthz", mw.NoCache, func(c *gin.Context) {
 		log.C(c).Infow("Healthz function called")
 
 		core.WriteResponse(c, nil, map[string]string{"status": "ok"})
@@ -34,21 +45,140 @@ func installRouters(g *gin.Engine) error {
 		return err
 	}
 
-	uc := user.New(store.S, authz)
+	// Authn 同时接受 JWT Token 和个人访问令牌
+	mw.UseAccessTokens(biz.NewBiz(store.S).Users())
+
+	uc := user.New(store.S, authz, authOptions())
+	pc := post.New(store.S, authz)
+	tc := tag.New(store.S)
+	cc := comment.New(store.S)
+	upc := upload.New(store.S, uploadOptions())
+	fc := feed.New(store.S, feedOptions())
+	wc, err := web.New(store.S, webOptions())
+	if err != nil {
+		return err
+	}
+
+	// 订阅源不需要认证，并且允许客户端缓存，通过 ETag 判断内容是否变化
+	g.GET("/feed.atom", fc.SiteAtom)
+	g.GET("/feed.rss", fc.SiteRSS)
+	g.GET("/users/:name/feed.atom", fc.UserAtom)
+	g.GET("/users/:name/feed.rss", fc.UserRSS)
+
+	// 公开的 HTML 页面，只显示已发布的公开博客，unlisted 博客只能通过链接访问
+	g.GET("/", wc.Index)
+	g.GET("/users/:name", wc.Blog)
+	g.GET("/users/:name/posts/:postID", wc.Post)
+	g.GET("/tags/:slug", wc.Tag)
+	g.GET("/assets/highlight.css", wc.StyleSheet)
+	g.Static("/static", filepath.Join(webOptions().ThemeDir, "static"))
+
+	// 上传的文件以内容摘要命名，不需要认证并且允许客户端永久缓存
+	g.GET("/media/:hash", upc.Media)
+
+	// 下游服务通过公钥离线验证 Token
+	g.GET("/.well-known/jwks.json", wellknown.JWKS)
 
-	g.POST("/login", uc.Login)
+	// 登录接口返回 Token，禁止客户端缓存
+	g.POST("/login", mw.NoCache, uc.Login)
+	g.POST("/login/2fa", mw.NoCache, uc.LoginTwoFactor) // 启用了两步验证的用户使用挑战令牌和一次性密码换取 Token
+	g.POST("/logout", mw.NoCache, uc.Logout)
 
-	// 创建 v1 路由分组
-	v1 := g.Group("/v1")
+	// 创建 v1 路由分组，API 返回与用户相关的数据，禁止客户端缓存
+	v1 := g.Group("/v1", mw.NoCache)
 	{
 		// 创建 users 路由分组
 		userv1 := v1.Group("/users")
 		{
 			userv1.POST("", uc.Create)
 			userv1.PUT(":name/change-password", uc.ChangePassword)
-			userv1.Use(mw.Authn(), mw.Authz(authz))
+			userv1.GET(":name/posts", mw.Authn(), mw.Scope(known.ScopePostsWrite), pc.ListByUser) // 获取指定用户的博客列表
+
+			// 以下接口读取需要 read scope，修改需要 users:write scope
+			namev1 := userv1.Group(":name", mw.Authn(), mw.Scope(known.ScopeUsersWrite))
+			{
+				namev1.PUT("follow", uc.Follow)           // 关注指定用户
+				namev1.DELETE("follow", uc.Unfollow)      // 取消关注指定用户
+				namev1.GET("followers", uc.ListFollowers) // 获取指定用户的关注者列表
+				namev1.GET("following", uc.ListFollowing) // 获取指定用户关注的用户列表
+
+				// 用户只能批准自己的关注者，批准的关注者可以看到仅关注者可见的博客
+				namev1.PUT("followers/:follower/approval", uc.ApproveFollower)   // 批准关注者
+				namev1.DELETE("followers/:follower/approval", uc.RevokeFollower) // 撤销批准
+
+				// 个人访问令牌用于自动化脚本，用户只能管理自己的令牌，并且不能使用个人访问令牌管理
+				namev1.POST("tokens", mw.Interactive(), uc.CreateAccessToken)            // 创建个人访问令牌
+				namev1.GET("tokens", mw.Interactive(), uc.ListAccessTokens)              // 获取个人访问令牌列表
+				namev1.DELETE("tokens/:tokenID", mw.Interactive(), uc.DeleteAccessToken) // 删除个人访问令牌
+
+				// 两步验证，关闭两步验证和重新生成恢复码都需要提供一次性密码，并且不能使用个人访问令牌
+				namev1.POST("totp", mw.Interactive(), uc.EnrollTOTP)                             // 开始绑定身份验证器
+				namev1.POST("totp/verify", mw.Interactive(), uc.VerifyTOTP)                      // 验证一次性密码并启用两步验证
+				namev1.DELETE("totp", mw.Interactive(), uc.DisableTOTP)                          // 关闭两步验证
+				namev1.POST("totp/recovery-codes", mw.Interactive(), uc.RegenerateRecoveryCodes) // 重新生成恢复码
+			}
+			userv1.Use(mw.Authn(), mw.Scope(known.ScopeUsersWrite), mw.Authz(authz))
 			userv1.GET(":name", uc.Get)
 		}
+
+		v1.POST("/auth/refresh", uc.Refresh) // 使用刷新令牌换取新的 Token
+
+		v1.GET("/timeline", mw.Authn(), mw.Scope(known.ScopePostsWrite), pc.Timeline) // 获取当前用户关注的用户发表的博客
+
+		// 创建 posts 路由分组，所有登录用户都可以读取博客，只有所有者才能修改和删除
+		postv1 := v1.Group("/posts", mw.Authn(), mw.Scope(known.ScopePostsWrite))
+		{
+			postv1.POST("", pc.Create)                           // 创建博客
+			postv1.GET("", pc.List)                              // 获取博客列表
+			postv1.DELETE("", pc.DeleteCollection)               // 批量删除当前用户的博客
+			postv1.GET("search", pc.Search)                      // 全文搜索博客
+			postv1.GET("trending", pc.Trending)                  // 获取热门博客
+			postv1.GET(":postID", pc.Get)                        // 获取博客详情
+			postv1.PUT(":postID", mw.Authz(authz), pc.Update)    // 更新博客
+			postv1.DELETE(":postID", mw.Authz(authz), pc.Delete) // 删除博客
+
+			// 博客的修订历史只有所有者可以访问，所有权在 biz 层校验
+			postv1.GET(":postID/revisions", pc.ListRevisions)                      // 获取修订历史
+			postv1.GET(":postID/revisions/diff", pc.DiffRevisions)                 // 对比两个修订
+			postv1.GET(":postID/revisions/:revision", pc.GetRevision)              // 获取指定修订
+			postv1.POST(":postID/revisions/:revision/restore", pc.RestoreRevision) // 恢复到指定修订
+
+			postv1.GET(":postID/views", pc.ListViews) // 获取浏览统计，只有所有者可以访问
+			postv1.GET(":postID/related", pc.Related) // 获取相关博客
+		}
+
+		// 创建博客互动的路由分组，回应和评论需要 comments:write scope
+		interactionv1 := v1.Group("/posts/:postID", mw.Authn(), mw.Scope(known.ScopeCommentsWrite))
+		{
+			// 所有登录用户都可以回应博客，每个用户对每种回应只能回应一次
+			interactionv1.PUT("reactions/:kind", pc.AddReaction)       // 回应博客
+			interactionv1.DELETE("reactions/:kind", pc.RemoveReaction) // 取消回应
+
+			// 所有登录用户都可以评论，评论的作者可以修改和删除评论，博客所有者可以删除博客下的任意评论
+			interactionv1.POST("comments", cc.Create)              // 发表评论
+			interactionv1.GET("comments", cc.List)                 // 获取评论列表
+			interactionv1.PUT("comments/:commentID", cc.Update)    // 修改评论
+			interactionv1.DELETE("comments/:commentID", cc.Delete) // 删除评论
+		}
+
+		// 创建 moderation 路由分组，用户只能审核自己博客下的评论
+		moderationv1 := v1.Group("/moderation", mw.Authn(), mw.Scope(known.ScopeCommentsWrite))
+		{
+			moderationv1.GET("queue", cc.ListQueue) // 获取待审核的评论
+			moderationv1.POST("queue", cc.Moderate) // 批量审核评论
+		}
+
+		// 创建 tags 路由分组
+		tagv1 := v1.Group("/tags", mw.Authn(), mw.Scope(known.ScopePostsWrite))
+		{
+			tagv1.GET("", tc.List) // 获取标签列表及使用次数
+		}
+
+		// 创建 uploads 路由分组
+		uploadv1 := v1.Group("/uploads", mw.Authn(), mw.Scope(known.ScopePostsWrite))
+		{
+			uploadv1.POST("", upc.Create) // 上传文件
+		}
 	}
 
 	return nil
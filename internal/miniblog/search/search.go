@@ -0,0 +1,197 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package search
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+var (
+	once sync.Once
+	// S 全局变量，方便其它包直接调用已初始化好的 S 实例.
+	S *Index
+)
+
+// Index 是基于 bleve 的博客全文索引，索引文件保存在本地磁盘上.
+type Index struct {
+	idx bleve.Index
+}
+
+// document 是写入索引的博客文档.
+type document struct {
+	Username  string    `json:"username"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Hit 是一条搜索结果.
+type Hit struct {
+	PostID    string
+	Username  string
+	Title     string
+	Score     float64
+	CreatedAt time.Time
+	// Highlights 是各字段中命中关键词的高亮片段，关键词使用 <mark> 标签包裹.
+	Highlights map[string][]string
+}
+
+// Options 定义了一次搜索的参数.
+type Options struct {
+	Query  string
+	Author string
+	Offset int
+	Limit  int
+}
+
+// NewIndex 打开 path 指定的索引，索引不存在时创建一个新的索引.
+func NewIndex(path string) (*Index, error) {
+	var err error
+	once.Do(func() {
+		var ins *Index
+		ins, err = Open(path)
+		S = ins
+	})
+
+	return S, err
+}
+
+// Open 打开 path 指定的索引，索引不存在时创建一个新的索引.
+// 与 NewIndex 不同，Open 不会设置全局变量 S.
+func Open(path string) (*Index, error) {
+	// 索引文件被其它进程占用时快速失败，而不是一直阻塞
+	idx, err := bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": "1s"})
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		idx, err = bleve.New(path, newMapping())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Index{idx: idx}, nil
+}
+
+// Rebuild 删除 path 处已有的索引并创建一个新的空索引.
+func Rebuild(path string) (*Index, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+
+	idx, err := bleve.New(path, newMapping())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Index{idx: idx}, nil
+}
+
+// newMapping 创建博客索引的映射：username 作为关键词精确匹配，title 和 content 分词后全文检索.
+func newMapping() mapping.IndexMapping {
+	keyword := bleve.NewKeywordFieldMapping()
+
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = standard.Name
+
+	date := bleve.NewDateTimeFieldMapping()
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt("username", keyword)
+	doc.AddFieldMappingsAt("title", text)
+	doc.AddFieldMappingsAt("content", text)
+	doc.AddFieldMappingsAt("createdAt", date)
+
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	m.DefaultAnalyzer = standard.Name
+
+	return m
+}
+
+// Index 将博客写入索引，已存在的同 postID 文档会被覆盖.
+func (i *Index) Index(posts ...*model.PostM) error {
+	batch := i.idx.NewBatch()
+	for _, post := range posts {
+		doc := &document{
+			Username:  post.Username,
+			Title:     post.Title,
+			Content:   post.Content,
+			CreatedAt: post.CreatedAt,
+		}
+		if err := batch.Index(post.PostID, doc); err != nil {
+			return err
+		}
+	}
+
+	return i.idx.Batch(batch)
+}
+
+// Delete 从索引中删除指定的博客.
+func (i *Index) Delete(postIDs ...string) error {
+	batch := i.idx.NewBatch()
+	for _, postID := range postIDs {
+		batch.Delete(postID)
+	}
+
+	return i.idx.Batch(batch)
+}
+
+// Search 按相关度返回匹配的博客，标题中的匹配比正文中的匹配权重更高.
+func (i *Index) Search(ctx context.Context, opts *Options) (int64, []*Hit, error) {
+	title := bleve.NewMatchQuery(opts.Query)
+	title.SetField("title")
+	title.SetBoost(2)
+
+	content := bleve.NewMatchQuery(opts.Query)
+	content.SetField("content")
+
+	var q query.Query = bleve.NewDisjunctionQuery(title, content)
+	if opts.Author != "" {
+		author := bleve.NewTermQuery(opts.Author)
+		author.SetField("username")
+		q = bleve.NewConjunctionQuery(q, author)
+	}
+
+	req := bleve.NewSearchRequestOptions(q, opts.Limit, opts.Offset, false)
+	req.Fields = []string{"username", "title", "createdAt"}
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	req.Highlight.AddField("title")
+	req.Highlight.AddField("content")
+
+	result, err := i.idx.SearchInContext(ctx, req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	hits := make([]*Hit, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		hit := &Hit{PostID: h.ID, Score: h.Score, Highlights: h.Fragments}
+		hit.Username, _ = h.Fields["username"].(string)
+		hit.Title, _ = h.Fields["title"].(string)
+		if createdAt, ok := h.Fields["createdAt"].(string); ok {
+			hit.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		}
+		hits = append(hits, hit)
+	}
+
+	return int64(result.Total), hits, nil
+}
+
+// Close 关闭索引，释放索引文件.
+func (i *Index) Close() error {
+	return i.idx.Close()
+}
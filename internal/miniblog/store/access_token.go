@@ -0,0 +1,85 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// AccessTokenStore 定义了 access_token 模块在 store 层所实现的方法.
+type AccessTokenStore interface {
+	Create(ctx context.Context, token *model.AccessTokenM) error
+	GetByHash(ctx context.Context, hash string) (*model.AccessTokenM, error)
+	List(ctx context.Context, username string) ([]*model.AccessTokenM, error)
+	CountActive(ctx context.Context, username string, now time.Time) (int64, error)
+	Delete(ctx context.Context, username, tokenID string) (bool, error)
+	Touch(ctx context.Context, id int64, now time.Time, interval time.Duration) error
+}
+
+// AccessTokenStore 接口的实现.
+type accessTokens struct {
+	db *gorm.DB
+}
+
+// 确保 accessTokens 实现了 AccessTokenStore 接口.
+var _ AccessTokenStore = (*accessTokens)(nil)
+
+func newAccessTokens(db *gorm.DB) *accessTokens {
+	return &accessTokens{db}
+}
+
+// Create 插入一条 access_token 记录.
+func (t *accessTokens) Create(ctx context.Context, token *model.AccessTokenM) error {
+	return t.db.Create(token).Error
+}
+
+// GetByHash 根据令牌摘要查询 access_token 记录.
+func (t *accessTokens) GetByHash(ctx context.Context, hash string) (*model.AccessTokenM, error) {
+	var token model.AccessTokenM
+	if err := t.db.Where("tokenHash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// List 按创建时间倒序返回用户的所有 access_token 记录.
+func (t *accessTokens) List(ctx context.Context, username string) ([]*model.AccessTokenM, error) {
+	var ret []*model.AccessTokenM
+	err := t.db.Where("username = ?", username).Order("id desc").Find(&ret).Error
+
+	return ret, err
+}
+
+// CountActive 返回用户在 now 时仍然有效的 access_token 数量.
+func (t *accessTokens) CountActive(ctx context.Context, username string, now time.Time) (int64, error) {
+	var count int64
+	err := t.db.Model(&model.AccessTokenM{}).Where("username = ? AND expiresAt > ?", username, now).Count(&count).Error
+
+	return count, err
+}
+
+// Delete 删除用户的一条 access_token 记录，记录不存在或者不属于 username 时返回 false.
+func (t *accessTokens) Delete(ctx context.Context, username, tokenID string) (bool, error) {
+	result := t.db.Where("username = ? AND tokenID = ?", username, tokenID).Delete(&model.AccessTokenM{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Touch 将令牌的最后使用时间更新为 now，距离上一次更新不足 interval 时不更新，避免每个请求都写数据库.
+func (t *accessTokens) Touch(ctx context.Context, id int64, now time.Time, interval time.Duration) error {
+	return t.db.Model(&model.AccessTokenM{}).
+		Where("id = ? AND (lastUsedAt IS NULL OR lastUsedAt < ?)", id, now.Add(-interval)).
+		UpdateColumn("lastUsedAt", now).Error
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// CommentStore 定义了 comment 模块在 store 层所实现的方法.
+type CommentStore interface {
+	Create(ctx context.Context, comment *model.CommentM) error
+	Get(ctx context.Context, commentID string) (*model.CommentM, error)
+	Update(ctx context.Context, comment *model.CommentM) error
+	ListRoots(ctx context.Context, postID, viewer string, page *core.Page) (int64, []*model.CommentM, string, error)
+	ListReplies(ctx context.Context, rootIDs []string, viewer string) ([]*model.CommentM, error)
+	CountReplies(ctx context.Context, commentID string) (int64, error)
+	ListQueue(ctx context.Context, owner, status string, page *core.Page) (int64, []*model.CommentM, string, error)
+	SetStatus(ctx context.Context, owner string, commentIDs []string, status string) (int64, error)
+	Delete(ctx context.Context, commentID string) error
+	DeleteByPosts(ctx context.Context, postIDs []string) error
+	CountApprovedDailySince(ctx context.Context, since time.Time) ([]*DailyCount, error)
+}
+
+// CommentStore 接口的实现.
+type comments struct {
+	db *gorm.DB
+}
+
+// 确保 comments 实现了 CommentStore 接口.
+var _ CommentStore = (*comments)(nil)
+
+func newComments(db *gorm.DB) *comments {
+	return &comments{db}
+}
+
+// Create 插入一条 comment 记录.
+func (c *comments) Create(ctx context.Context, comment *model.CommentM) error {
+	return c.db.Create(comment).Error
+}
+
+// Get 根据 commentID 查询 comment 数据库记录.
+func (c *comments) Get(ctx context.Context, commentID string) (*model.CommentM, error) {
+	var comment model.CommentM
+	if err := c.db.Where("commentID = ?", commentID).First(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// Update 更新一条 comment 数据库记录.
+func (c *comments) Update(ctx context.Context, comment *model.CommentM) error {
+	return c.db.Save(comment).Error
+}
+
+// ListRoots 按创建时间倒序分页返回直接评论博客的已公开评论和 viewer 自己等待审核的评论，即每个讨论串的第一条评论.
+func (c *comments) ListRoots(ctx context.Context, postID, viewer string, page *core.Page) (count int64, ret []*model.CommentM, next string, err error) {
+	db := c.db.Model(&model.CommentM{}).
+		Where("postID = ? AND parentID = ''", postID).
+		Where(c.visibleTo(viewer)).
+		Session(&gorm.Session{})
+	if err = db.Count(&count).Error; err != nil {
+		return
+	}
+
+	ret, next, err = core.Paginate(db, page, "", func(comment *model.CommentM) core.Cursor {
+		return core.Cursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+	})
+
+	return
+}
+
+// ListReplies 按创建时间正序返回指定讨论串中的全部已公开回复和 viewer 自己等待审核的回复，不包含讨论串的第一条评论.
+func (c *comments) ListReplies(ctx context.Context, rootIDs []string, viewer string) ([]*model.CommentM, error) {
+	var ret []*model.CommentM
+	err := c.db.Where("rootID in (?) AND parentID <> ''", rootIDs).
+		Where(c.visibleTo(viewer)).
+		Order("createdAt, id").
+		Find(&ret).Error
+
+	return ret, err
+}
+
+// visibleTo 返回 viewer 可以在评论列表中看到的评论的查询条件.
+func (c *comments) visibleTo(viewer string) *gorm.DB {
+	return c.db.Where("status = ?", model.CommentStatusApproved).
+		Or("status = ? AND username = ?", model.CommentStatusPending, viewer)
+}
+
+// CountReplies 返回对指定评论的直接回复数.
+func (c *comments) CountReplies(ctx context.Context, commentID string) (int64, error) {
+	var count int64
+	err := c.db.Model(&model.CommentM{}).Where("parentID = ?", commentID).Count(&count).Error
+
+	return count, err
+}
+
+// ListQueue 按创建时间倒序分页返回 owner 的博客下指定审核状态的评论，已删除的评论不会返回.
+func (c *comments) ListQueue(ctx context.Context, owner, status string, page *core.Page) (count int64, ret []*model.CommentM, next string, err error) {
+	db := c.db.Model(&model.CommentM{}).
+		Where("postID in (?)", c.ownedPosts(owner)).
+		Where("status = ? AND deleted = ?", status, false).
+		Session(&gorm.Session{})
+	if err = db.Count(&count).Error; err != nil {
+		return
+	}
+
+	ret, next, err = core.Paginate(db, page, "", func(comment *model.CommentM) core.Cursor {
+		return core.Cursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+	})
+
+	return
+}
+
+// SetStatus 修改 owner 的博客下指定评论的审核状态，返回实际修改的评论数，不属于 owner 博客的评论会被忽略.
+func (c *comments) SetStatus(ctx context.Context, owner string, commentIDs []string, status string) (int64, error) {
+	result := c.db.Model(&model.CommentM{}).
+		Where("commentID in (?) AND postID in (?)", commentIDs, c.ownedPosts(owner)).
+		Update("status", status)
+
+	return result.RowsAffected, result.Error
+}
+
+// ownedPosts 返回查询 owner 所有博客 postID 的子查询.
+func (c *comments) ownedPosts(owner string) *gorm.DB {
+	return c.db.Model(&model.PostM{}).Select("postID").Where("username = ?", owner)
+}
+
+// Delete 删除一条 comment 数据库记录.
+func (c *comments) Delete(ctx context.Context, commentID string) error {
+	return c.db.Where("commentID = ?", commentID).Delete(&model.CommentM{}).Error
+}
+
+// DeleteByPosts 删除指定博客的全部评论.
+func (c *comments) DeleteByPosts(ctx context.Context, postIDs []string) error {
+	return c.db.Where("postID in (?)", postIDs).Delete(&model.CommentM{}).Error
+}
+
+// CountApprovedDailySince 按博客和日期分组统计 since 之后发表的已公开并且没有被删除的评论数.
+func (c *comments) CountApprovedDailySince(ctx context.Context, since time.Time) ([]*DailyCount, error) {
+	var ret []*DailyCount
+	err := c.db.Model(&model.CommentM{}).
+		Select("postID, DATE(createdAt) AS day, COUNT(*) AS count").
+		Where("createdAt >= ? AND status = ? AND deleted = ?", since, model.CommentStatusApproved, false).
+		Group("postID, DATE(createdAt)").
+		Scan(&ret).Error
+
+	return ret, err
+}
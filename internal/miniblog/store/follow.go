@@ -0,0 +1,108 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// FollowStore 定义了 follow 模块在 store 层所实现的方法.
+type FollowStore interface {
+	Create(ctx context.Context, follow *model.FollowM) error
+	Delete(ctx context.Context, follower, followee string) error
+	ListFollowers(ctx context.Context, username string, page *core.Page) (int64, []*model.FollowM, string, error)
+	ListFollowing(ctx context.Context, username string, page *core.Page) (int64, []*model.FollowM, string, error)
+	Followees(ctx context.Context, username string) ([]*model.FollowM, error)
+	Approve(ctx context.Context, follower, followee string, approved bool) (bool, error)
+	IsApproved(ctx context.Context, follower, followee string) (bool, error)
+}
+
+// FollowStore 接口的实现.
+type follows struct {
+	db *gorm.DB
+}
+
+// 确保 follows 实现了 FollowStore 接口.
+var _ FollowStore = (*follows)(nil)
+
+func newFollows(db *gorm.DB) *follows {
+	return &follows{db}
+}
+
+// Create 插入一条 follow 记录，记录已经存在时忽略.
+func (f *follows) Create(ctx context.Context, follow *model.FollowM) error {
+	return f.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "follower"}, {Name: "followee"}},
+		DoNothing: true,
+	}).Create(follow).Error
+}
+
+// Delete 删除一条 follow 记录.
+func (f *follows) Delete(ctx context.Context, follower, followee string) error {
+	return f.db.Where("follower = ? AND followee = ?", follower, followee).Delete(&model.FollowM{}).Error
+}
+
+// ListFollowers 按关注时间倒序分页返回关注了 username 的记录.
+func (f *follows) ListFollowers(ctx context.Context, username string, page *core.Page) (int64, []*model.FollowM, string, error) {
+	return f.list(f.db.Model(&model.FollowM{}).Where("followee = ?", username), page)
+}
+
+// ListFollowing 按关注时间倒序分页返回 username 关注其他用户的记录.
+func (f *follows) ListFollowing(ctx context.Context, username string, page *core.Page) (int64, []*model.FollowM, string, error) {
+	return f.list(f.db.Model(&model.FollowM{}).Where("follower = ?", username), page)
+}
+
+// Followees 返回 username 关注其他用户的全部记录.
+func (f *follows) Followees(ctx context.Context, username string) ([]*model.FollowM, error) {
+	var ret []*model.FollowM
+	err := f.db.Where("follower = ?", username).Find(&ret).Error
+
+	return ret, err
+}
+
+// Approve 设置 followee 是否批准了 follower 的关注，关注记录不存在时返回 false.
+func (f *follows) Approve(ctx context.Context, follower, followee string, approved bool) (bool, error) {
+	var follow model.FollowM
+	err := f.db.Where("follower = ? AND followee = ?", follower, followee).First(&follow).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, f.db.Model(&follow).UpdateColumn("approved", approved).Error
+}
+
+// IsApproved 返回 follower 是否是 followee 批准的关注者.
+func (f *follows) IsApproved(ctx context.Context, follower, followee string) (bool, error) {
+	var count int64
+	err := f.db.Model(&model.FollowM{}).Where("follower = ? AND followee = ? AND approved = ?", follower, followee, true).Count(&count).Error
+
+	return count > 0, err
+}
+
+// list 分页返回满足 db 查询条件的 follow 记录.
+func (f *follows) list(db *gorm.DB, page *core.Page) (count int64, ret []*model.FollowM, next string, err error) {
+	db = db.Session(&gorm.Session{})
+	if err = db.Count(&count).Error; err != nil {
+		return
+	}
+
+	ret, next, err = core.Paginate(db, page, "", func(follow *model.FollowM) core.Cursor {
+		return core.Cursor{CreatedAt: follow.CreatedAt, ID: follow.ID}
+	})
+
+	return
+}
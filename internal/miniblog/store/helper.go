@@ -0,0 +1,16 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import "strings"
+
+// likeEscaper 用来转义 LIKE 语句中的通配符.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// prefixPattern 返回匹配指定前缀的 LIKE 模式串.
+func prefixPattern(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
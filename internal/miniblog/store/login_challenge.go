@@ -0,0 +1,82 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// LoginChallengeStore 定义了 login_challenge 模块在 store 层所实现的方法.
+type LoginChallengeStore interface {
+	Create(ctx context.Context, challenge *model.LoginChallengeM) error
+	GetByHash(ctx context.Context, hash string) (*model.LoginChallengeM, error)
+	Attempt(ctx context.Context, id int64, max int) (bool, error)
+	Delete(ctx context.Context, id int64) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoginChallengeStore 接口的实现.
+type loginChallenges struct {
+	db *gorm.DB
+}
+
+// 确保 loginChallenges 实现了 LoginChallengeStore 接口.
+var _ LoginChallengeStore = (*loginChallenges)(nil)
+
+func newLoginChallenges(db *gorm.DB) *loginChallenges {
+	return &loginChallenges{db}
+}
+
+// Create 插入一条 login_challenge 记录.
+func (c *loginChallenges) Create(ctx context.Context, challenge *model.LoginChallengeM) error {
+	return c.db.Create(challenge).Error
+}
+
+// GetByHash 根据挑战令牌的摘要查询 login_challenge 记录.
+func (c *loginChallenges) GetByHash(ctx context.Context, hash string) (*model.LoginChallengeM, error) {
+	var challenge model.LoginChallengeM
+	if err := c.db.Where("tokenHash = ?", hash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// Attempt 在验证一次性密码之前将挑战的验证次数加一，次数已经达到 max 时返回 false.
+// 检查和累加在同一条 UPDATE 语句中完成，并发请求的验证次数也不会超过 max.
+func (c *loginChallenges) Attempt(ctx context.Context, id int64, max int) (bool, error) {
+	result := c.db.Model(&model.LoginChallengeM{}).
+		Where("id = ? AND attempts < ?", id, max).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Delete 删除一条 login_challenge 记录，记录已经被删除时返回 false.
+// 同一个挑战令牌被同时使用多次时，只有一个请求可以删除成功.
+func (c *loginChallenges) Delete(ctx context.Context, id int64) (bool, error) {
+	result := c.db.Where("id = ?", id).Delete(&model.LoginChallengeM{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteExpired 删除在 now 之前过期的 login_challenge 记录，返回删除的记录数.
+func (c *loginChallenges) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := c.db.Where("expiresAt <= ?", now).Delete(&model.LoginChallengeM{})
+
+	return result.RowsAffected, result.Error
+}
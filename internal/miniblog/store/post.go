@@ -0,0 +1,295 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// PostStore 定义了 post 模块在 store 层所实现的方法.
+type PostStore interface {
+	Create(ctx context.Context, post *model.PostM) error
+	Get(ctx context.Context, postID string) (*model.PostM, error)
+	Update(ctx context.Context, post *model.PostM, revision int) (bool, error)
+	List(ctx context.Context, filter *PostFilter, page *core.Page) (int64, []*model.PostM, string, error)
+	Timeline(ctx context.Context, sources []TimelineSource, page *core.Page) ([]*model.PostM, string, error)
+	Find(ctx context.Context, filter *PostFilter) ([]*model.PostM, error)
+	Delete(ctx context.Context, username string, postIDs []string) ([]*model.PostM, error)
+	ClaimScheduled(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]*model.PostM, error)
+	Publish(ctx context.Context, post *model.PostM, owner string) (bool, error)
+}
+
+// PostFilter 定义了查询 post 列表时的过滤条件，零值字段表示不过滤.
+type PostFilter struct {
+	// Username 只返回指定作者的 post.
+	Username string
+	// Usernames 只返回作者在列表中的 post，不为 nil 时即使为空也会生效.
+	Usernames []string
+	// PostIDs 只返回 postID 在列表中的 post，不为 nil 时即使为空也会生效.
+	PostIDs []string
+	// Viewer 是发起查询的用户，除了 Viewer 自己的 post 之外，只返回已发布的公开 post 和
+	// Viewer 作为批准的关注者可以看到的 post. unlisted post 只能通过链接访问，不会出现在其他用户的列表中.
+	// 为空时只返回已发布的公开 post.
+	Viewer string
+	// Status 只返回指定状态的 post.
+	Status string
+	// TitlePrefix 只返回标题以指定前缀开头的 post.
+	TitlePrefix string
+	// Tag 只返回带有指定标签的 post，值为标签的 slug.
+	Tag string
+	// Since 和 Until 限定 post 的创建时间范围，包含 Since，不包含 Until.
+	Since time.Time
+	Until time.Time
+}
+
+// TimelineSource 是时间线中的一个作者，Visibilities 是读者可以在时间线中看到的该作者博客的可见范围.
+type TimelineSource struct {
+	Username     string
+	Visibilities []string
+}
+
+// PostStore 接口的实现.
+type posts struct {
+	db *gorm.DB
+}
+
+// 确保 posts 实现了 PostStore 接口.
+var _ PostStore = (*posts)(nil)
+
+func newPosts(db *gorm.DB) *posts {
+	return &posts{db}
+}
+
+// Create 插入一条 post 记录.
+func (p *posts) Create(ctx context.Context, post *model.PostM) error {
+	return p.db.Create(&post).Error
+}
+
+// Get 根据 postID 查询 post 数据库记录.
+func (p *posts) Get(ctx context.Context, postID string) (*model.PostM, error) {
+	var post model.PostM
+	if err := p.db.Where("postID = ?", postID).First(&post).Error; err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// Update 更新一条 post 数据库记录，只有数据库中记录的修订号仍然等于 revision 时才会更新.
+// 返回 false 表示记录已经被其它请求修改，调用方应该重新读取后再修改.
+// viewCount 由 ViewStore 单独累加，这里不会覆盖.
+func (p *posts) Update(ctx context.Context, post *model.PostM, revision int) (bool, error) {
+	result := p.db.Model(post).Where("revision = ?", revision).Select("*").Omit("id", "createdAt", "viewCount").Updates(post)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// List 根据过滤条件按创建时间倒序分页返回 post 列表、满足条件的总数以及下一页的游标.
+func (p *posts) List(ctx context.Context, filter *PostFilter, page *core.Page) (count int64, ret []*model.PostM, next string, err error) {
+	// 开启新的会话，使 Count 和分页查询共享过滤条件而互不影响
+	db := p.where(filter).Session(&gorm.Session{})
+	if err = db.Count(&count).Error; err != nil {
+		return
+	}
+
+	ret, next, err = core.Paginate(db, page, "", postCursor)
+
+	return
+}
+
+// Timeline 按发布时间倒序分页返回 sources 中的作者已发布的 post 以及下一页的游标，游标中的时间是发布时间.
+//
+// 每个作者在 (username, status, publishedAt, id, visibility) 索引上单独做一次 LIMIT 范围扫描，不需要排序，
+// 所有扫描通过 UNION ALL 在一次查询中执行并且只读取索引中的 id 和 publishedAt，在内存中合并之后再读取当前页的完整记录.
+// 单页的代价只与作者数和每页的记录数有关，与每个作者的博客数无关.
+func (p *posts) Timeline(ctx context.Context, sources []TimelineSource, page *core.Page) ([]*model.PostM, string, error) {
+	if len(sources) == 0 {
+		return nil, "", nil
+	}
+
+	scans := make([]interface{}, 0, len(sources))
+	for _, source := range sources {
+		scan := p.db.Model(&model.PostM{}).
+			Select("id", "publishedAt").
+			Where("username = ? AND status = ? AND visibility IN (?)", source.Username, model.PostStatusPublished, source.Visibilities).
+			Where("publishedAt IS NOT NULL")
+		if page.Cursor != nil {
+			scan = scan.Where("publishedAt < ? OR (publishedAt = ? AND id < ?)", page.Cursor.CreatedAt, page.Cursor.CreatedAt, page.Cursor.ID)
+		}
+		// 多查询一条记录，用来判断是否还有下一页
+		scans = append(scans, scan.Order("publishedAt desc").Order("id desc").Limit(page.Limit+1))
+	}
+
+	var heads []*model.PostM
+	if err := p.db.Raw(strings.Repeat("(?) UNION ALL ", len(scans)-1)+"(?)", scans...).Scan(&heads).Error; err != nil {
+		return nil, "", err
+	}
+	if len(heads) == 0 {
+		return nil, "", nil
+	}
+
+	sort.Slice(heads, func(i, j int) bool {
+		a, b := heads[i], heads[j]
+		if !a.PublishedAt.Equal(*b.PublishedAt) {
+			return a.PublishedAt.After(*b.PublishedAt)
+		}
+
+		return a.ID > b.ID
+	})
+
+	var next string
+	if len(heads) > page.Limit {
+		heads = heads[:page.Limit]
+		last := heads[len(heads)-1]
+		next = core.Cursor{CreatedAt: *last.PublishedAt, ID: last.ID}.Encode()
+	}
+
+	ids := make([]int64, 0, len(heads))
+	for _, head := range heads {
+		ids = append(ids, head.ID)
+	}
+
+	var ret []*model.PostM
+	if err := p.db.Where("id IN (?)", ids).Order("publishedAt desc").Order("id desc").Find(&ret).Error; err != nil {
+		return nil, "", err
+	}
+
+	return ret, next, nil
+}
+
+// Find 返回满足过滤条件的全部 post，不分页，调用方需要通过 PostIDs 等条件限制返回的数量.
+func (p *posts) Find(ctx context.Context, filter *PostFilter) ([]*model.PostM, error) {
+	var ret []*model.PostM
+	err := p.where(filter).Find(&ret).Error
+
+	return ret, err
+}
+
+// where 返回应用了过滤条件的查询.
+func (p *posts) where(filter *PostFilter) *gorm.DB {
+	db := p.db.Model(&model.PostM{})
+	if filter.Username != "" {
+		db = db.Where("username = ?", filter.Username)
+	}
+	if filter.Usernames != nil {
+		db = db.Where("username in (?)", filter.Usernames)
+	}
+	if filter.PostIDs != nil {
+		db = db.Where("postID in (?)", filter.PostIDs)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	db = db.Where(p.visible(filter.Viewer))
+	if filter.TitlePrefix != "" {
+		db = db.Where("title LIKE ?", prefixPattern(filter.TitlePrefix))
+	}
+	if filter.Tag != "" {
+		tagged := p.db.Table("post_tag").
+			Select("post_tag.postID").
+			Joins("JOIN tag ON tag.id = post_tag.tagID").
+			Where("tag.slug = ?", filter.Tag)
+		db = db.Where("postID in (?)", tagged)
+	}
+	if !filter.Since.IsZero() {
+		db = db.Where("createdAt >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		db = db.Where("createdAt < ?", filter.Until)
+	}
+
+	return db
+}
+
+// visible 返回 viewer 可以在列表中看到的 post 的查询条件，与 model.PostM.VisibleTo 保持一致，只是不包含 unlisted post.
+func (p *posts) visible(viewer string) *gorm.DB {
+	cond := p.db.Where("status = ? AND visibility = ?", model.PostStatusPublished, model.PostVisibilityPublic)
+	if viewer == "" {
+		return cond
+	}
+
+	approved := p.db.Model(&model.FollowM{}).Select("followee").Where("follower = ? AND approved = ?", viewer, true)
+
+	return cond.
+		Or("status = ? AND visibility = ? AND username IN (?)", model.PostStatusPublished, model.PostVisibilityFollowers, approved).
+		Or("username = ?", viewer)
+}
+
+// Delete 根据 username, postID 删除数据库 post 记录，返回实际删除的 post.
+// 不属于 username 的 postID 会被忽略.
+func (p *posts) Delete(ctx context.Context, username string, postIDs []string) ([]*model.PostM, error) {
+	var deleted []*model.PostM
+	if err := p.db.Where("username = ? and postID in (?)", username, postIDs).Find(&deleted).Error; err != nil || len(deleted) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(deleted))
+	for _, post := range deleted {
+		ids = append(ids, post.ID)
+	}
+
+	err := p.db.Where("id in (?)", ids).Delete(&model.PostM{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// ClaimScheduled 认领最多 limit 条到达发布时间的定时博客，并返回认领成功的博客.
+// 认领通过一条带条件的 UPDATE 语句完成，多个 miniblog 实例同时认领时，每条记录只会被一个实例认领成功.
+// 认领超过 lease 仍未发布的记录（例如认领它的实例已经退出）可以被重新认领.
+func (p *posts) ClaimScheduled(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]*model.PostM, error) {
+	err := p.db.Model(&model.PostM{}).
+		Where("status = ? AND publishAt <= ?", model.PostStatusScheduled, now).
+		Where("claimedBy = '' OR claimedAt < ?", now.Add(-lease)).
+		Order("publishAt").
+		Limit(limit).
+		UpdateColumns(map[string]interface{}{"claimedBy": owner, "claimedAt": now}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 同时返回之前认领但没有发布成功的记录，以便重试
+	var claimed []*model.PostM
+	err = p.db.Where("status = ? AND claimedBy = ?", model.PostStatusScheduled, owner).Find(&claimed).Error
+
+	return claimed, err
+}
+
+// Publish 将 owner 认领的定时博客设置为已发布，如果博客已经被修改或者被其它实例重新认领则返回 false.
+func (p *posts) Publish(ctx context.Context, post *model.PostM, owner string) (bool, error) {
+	result := p.db.Model(&model.PostM{}).
+		Where("id = ? AND status = ? AND claimedBy = ?", post.ID, model.PostStatusScheduled, owner).
+		UpdateColumns(map[string]interface{}{
+			"status":      model.PostStatusPublished,
+			"publishedAt": post.PublishAt,
+			"claimedBy":   "",
+			"claimedAt":   nil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// postCursor 返回 post 记录对应的分页游标.
+func postCursor(post *model.PostM) core.Cursor {
+	return core.Cursor{CreatedAt: post.CreatedAt, ID: post.ID}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/core"
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// PostRevisionStore 定义了 post_revision 模块在 store 层所实现的方法.
+type PostRevisionStore interface {
+	Create(ctx context.Context, revision *model.PostRevisionM) error
+	Get(ctx context.Context, postID string, revision int) (*model.PostRevisionM, error)
+	List(ctx context.Context, postID string, page *core.Page) (int64, []*model.PostRevisionM, string, error)
+	Delete(ctx context.Context, postIDs []string) error
+}
+
+// PostRevisionStore 接口的实现.
+type postRevisions struct {
+	db *gorm.DB
+}
+
+// 确保 postRevisions 实现了 PostRevisionStore 接口.
+var _ PostRevisionStore = (*postRevisions)(nil)
+
+func newPostRevisions(db *gorm.DB) *postRevisions {
+	return &postRevisions{db}
+}
+
+// Create 插入一条 post_revision 记录.
+func (r *postRevisions) Create(ctx context.Context, revision *model.PostRevisionM) error {
+	return r.db.Create(revision).Error
+}
+
+// Get 根据 postID 和修订号查询 post_revision 记录.
+func (r *postRevisions) Get(ctx context.Context, postID string, revision int) (*model.PostRevisionM, error) {
+	var ret model.PostRevisionM
+	if err := r.db.Where("postID = ? AND revision = ?", postID, revision).First(&ret).Error; err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// List 按创建时间倒序分页返回博客的修订记录.
+func (r *postRevisions) List(ctx context.Context, postID string, page *core.Page) (count int64, ret []*model.PostRevisionM, next string, err error) {
+	db := r.db.Model(&model.PostRevisionM{}).Where("postID = ?", postID).Session(&gorm.Session{})
+	if err = db.Count(&count).Error; err != nil {
+		return
+	}
+
+	ret, next, err = core.Paginate(db, page, "", func(revision *model.PostRevisionM) core.Cursor {
+		return core.Cursor{CreatedAt: revision.CreatedAt, ID: revision.ID}
+	})
+
+	return
+}
+
+// Delete 删除指定博客的所有修订记录.
+func (r *postRevisions) Delete(ctx context.Context, postIDs []string) error {
+	return r.db.Where("postID in (?)", postIDs).Delete(&model.PostRevisionM{}).Error
+}
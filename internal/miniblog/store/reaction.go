@@ -0,0 +1,102 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// ReactionStore 定义了 reaction 模块在 store 层所实现的方法.
+type ReactionStore interface {
+	Create(ctx context.Context, reaction *model.ReactionM) (bool, error)
+	Delete(ctx context.Context, postID, username, kind string) (bool, error)
+	IncrCount(ctx context.Context, postID, kind string, delta int64) error
+	ListCounts(ctx context.Context, postIDs []string) (map[string]map[string]int64, error)
+	CountDailySince(ctx context.Context, since time.Time) ([]*DailyCount, error)
+	DeleteByPosts(ctx context.Context, postIDs []string) error
+}
+
+// ReactionStore 接口的实现.
+type reactions struct {
+	db *gorm.DB
+}
+
+// 确保 reactions 实现了 ReactionStore 接口.
+var _ ReactionStore = (*reactions)(nil)
+
+func newReactions(db *gorm.DB) *reactions {
+	return &reactions{db}
+}
+
+// Create 插入一条 post_reaction 记录，记录已经存在时返回 false.
+func (r *reactions) Create(ctx context.Context, reaction *model.ReactionM) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "postID"}, {Name: "username"}, {Name: "kind"}},
+		DoNothing: true,
+	}).Create(reaction)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// Delete 删除一条 post_reaction 记录，记录不存在时返回 false.
+func (r *reactions) Delete(ctx context.Context, postID, username, kind string) (bool, error) {
+	result := r.db.Where("postID = ? AND username = ? AND kind = ?", postID, username, kind).Delete(&model.ReactionM{})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// IncrCount 将博客指定回应的数量增加 delta，delta 为负数时表示减少.
+func (r *reactions) IncrCount(ctx context.Context, postID, kind string, delta int64) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "postID"}, {Name: "kind"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("count + ?", delta)}),
+	}).Create(&model.ReactionCountM{PostID: postID, Kind: kind, Count: delta}).Error
+}
+
+// ListCounts 返回指定博客各种回应的数量，以 postID 为键，数量为 0 的回应不会返回.
+func (r *reactions) ListCounts(ctx context.Context, postIDs []string) (map[string]map[string]int64, error) {
+	var counts []*model.ReactionCountM
+	if err := r.db.Where("postID in (?) AND count > 0", postIDs).Find(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]map[string]int64, len(postIDs))
+	for _, c := range counts {
+		if ret[c.PostID] == nil {
+			ret[c.PostID] = make(map[string]int64)
+		}
+		ret[c.PostID][c.Kind] = c.Count
+	}
+
+	return ret, nil
+}
+
+// DeleteByPosts 删除指定博客的全部回应及回应数量.
+func (r *reactions) DeleteByPosts(ctx context.Context, postIDs []string) error {
+	if err := r.db.Where("postID in (?)", postIDs).Delete(&model.ReactionM{}).Error; err != nil {
+		return err
+	}
+
+	return r.db.Where("postID in (?)", postIDs).Delete(&model.ReactionCountM{}).Error
+}
+
+// CountDailySince 按博客和日期分组统计 since 之后的回应数.
+func (r *reactions) CountDailySince(ctx context.Context, since time.Time) ([]*DailyCount, error) {
+	var ret []*DailyCount
+	err := r.db.Model(&model.ReactionM{}).
+		Select("postID, DATE(createdAt) AS day, COUNT(*) AS count").
+		Where("createdAt >= ?", since).
+		Group("postID, DATE(createdAt)").
+		Scan(&ret).Error
+
+	return ret, err
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// RecoveryCodeStore 定义了 recovery_code 模块在 store 层所实现的方法.
+type RecoveryCodeStore interface {
+	Create(ctx context.Context, codes []*model.RecoveryCodeM) error
+	Use(ctx context.Context, username, hash string, now time.Time) (bool, error)
+	DeleteByUser(ctx context.Context, username string) error
+}
+
+// RecoveryCodeStore 接口的实现.
+type recoveryCodes struct {
+	db *gorm.DB
+}
+
+// 确保 recoveryCodes 实现了 RecoveryCodeStore 接口.
+var _ RecoveryCodeStore = (*recoveryCodes)(nil)
+
+func newRecoveryCodes(db *gorm.DB) *recoveryCodes {
+	return &recoveryCodes{db}
+}
+
+// Create 批量插入 recovery_code 记录.
+func (c *recoveryCodes) Create(ctx context.Context, codes []*model.RecoveryCodeM) error {
+	return c.db.Create(&codes).Error
+}
+
+// Use 将用户摘要为 hash 的恢复码标记为已使用，恢复码不存在或者已经使用时返回 false.
+func (c *recoveryCodes) Use(ctx context.Context, username, hash string, now time.Time) (bool, error) {
+	result := c.db.Model(&model.RecoveryCodeM{}).
+		Where("username = ? AND codeHash = ? AND usedAt IS NULL", username, hash).
+		UpdateColumn("usedAt", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUser 删除用户所有的恢复码.
+func (c *recoveryCodes) DeleteByUser(ctx context.Context, username string) error {
+	return c.db.Where("username = ?", username).Delete(&model.RecoveryCodeM{}).Error
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// RefreshTokenStore 定义了 refresh_token 模块在 store 层所实现的方法.
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token *model.RefreshTokenM) error
+	GetByHash(ctx context.Context, hash string) (*model.RefreshTokenM, error)
+	Rotate(ctx context.Context, id int64, now time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, family string, now time.Time) error
+	RevokeByUser(ctx context.Context, username string, now time.Time) error
+}
+
+// RefreshTokenStore 接口的实现.
+type refreshTokens struct {
+	db *gorm.DB
+}
+
+// 确保 refreshTokens 实现了 RefreshTokenStore 接口.
+var _ RefreshTokenStore = (*refreshTokens)(nil)
+
+func newRefreshTokens(db *gorm.DB) *refreshTokens {
+	return &refreshTokens{db}
+}
+
+// Create 插入一条 refresh_token 记录.
+func (t *refreshTokens) Create(ctx context.Context, token *model.RefreshTokenM) error {
+	return t.db.Create(token).Error
+}
+
+// GetByHash 根据令牌摘要查询 refresh_token 记录.
+func (t *refreshTokens) GetByHash(ctx context.Context, hash string) (*model.RefreshTokenM, error) {
+	var token model.RefreshTokenM
+	if err := t.db.Where("tokenHash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Rotate 将令牌标记为已轮换，令牌已经被轮换或者撤销时返回 false.
+// 多个请求同时使用同一个令牌时，只有一个请求可以轮换成功.
+func (t *refreshTokens) Rotate(ctx context.Context, id int64, now time.Time) (bool, error) {
+	result := t.db.Model(&model.RefreshTokenM{}).
+		Where("id = ? AND rotatedAt IS NULL AND revokedAt IS NULL", id).
+		UpdateColumn("rotatedAt", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily 撤销令牌族中所有还没有撤销的令牌.
+func (t *refreshTokens) RevokeFamily(ctx context.Context, family string, now time.Time) error {
+	return t.db.Model(&model.RefreshTokenM{}).
+		Where("family = ? AND revokedAt IS NULL", family).
+		UpdateColumn("revokedAt", now).Error
+}
+
+// RevokeByUser 撤销用户所有还没有撤销的令牌.
+func (t *refreshTokens) RevokeByUser(ctx context.Context, username string, now time.Time) error {
+	return t.db.Model(&model.RefreshTokenM{}).
+		Where("username = ? AND revokedAt IS NULL", username).
+		UpdateColumn("revokedAt", now).Error
+}
@@ -6,6 +6,7 @@
 package store
 
 import (
+	"context"
 	"sync"
 
 	"gorm.io/gorm"
@@ -20,7 +21,21 @@ var (
 // IStore 定义了 Store 层需要实现的方法.
 type IStore interface {
 	Users() UserStore
+	Posts() PostStore
+	PostRevisions() PostRevisionStore
+	Tags() TagStore
+	Comments() CommentStore
+	Reactions() ReactionStore
+	Follows() FollowStore
+	Uploads() UploadStore
+	Views() ViewStore
+	RefreshTokens() RefreshTokenStore
+	AccessTokens() AccessTokenStore
+	TOTPs() TOTPStore
+	RecoveryCodes() RecoveryCodeStore
+	LoginChallenges() LoginChallengeStore
 	DB() *gorm.DB
+	TX(ctx context.Context, fn func(ds IStore) error) error
 }
 
 // datastore 是 IStore 的一个具体实现.
@@ -46,6 +61,80 @@ func (ds *datastore) Users() UserStore {
 	return newUsers(ds.db)
 }
 
+// Posts 返回一个实现了 PostStore 接口的实例.
+func (ds *datastore) Posts() PostStore {
+	return newPosts(ds.db)
+}
+
+// PostRevisions 返回一个实现了 PostRevisionStore 接口的实例.
+func (ds *datastore) PostRevisions() PostRevisionStore {
+	return newPostRevisions(ds.db)
+}
+
+// Tags 返回一个实现了 TagStore 接口的实例.
+func (ds *datastore) Tags() TagStore {
+	return newTags(ds.db)
+}
+
+// Comments 返回一个实现了 CommentStore 接口的实例.
+func (ds *datastore) Comments() CommentStore {
+	return newComments(ds.db)
+}
+
+// Reactions 返回一个实现了 ReactionStore 接口的实例.
+func (ds *datastore) Reactions() ReactionStore {
+	return newReactions(ds.db)
+}
+
+// Follows 返回一个实现了 FollowStore 接口的实例.
+func (ds *datastore) Follows() FollowStore {
+	return newFollows(ds.db)
+}
+
+// Uploads 返回一个实现了 UploadStore 接口的实例.
+func (ds *datastore) Uploads() UploadStore {
+	return newUploads(ds.db)
+}
+
+// Views 返回一个实现了 ViewStore 接口的实例.
+func (ds *datastore) Views() ViewStore {
+	return newViews(ds.db)
+}
+
+// RefreshTokens 返回一个实现了 RefreshTokenStore 接口的实例.
+func (ds *datastore) RefreshTokens() RefreshTokenStore {
+	return newRefreshTokens(ds.db)
+}
+
+// AccessTokens 返回一个实现了 AccessTokenStore 接口的实例.
+func (ds *datastore) AccessTokens() AccessTokenStore {
+	return newAccessTokens(ds.db)
+}
+
+// TOTPs 返回一个实现了 TOTPStore 接口的实例.
+func (ds *datastore) TOTPs() TOTPStore {
+	return newTOTPs(ds.db)
+}
+
+// RecoveryCodes 返回一个实现了 RecoveryCodeStore 接口的实例.
+func (ds *datastore) RecoveryCodes() RecoveryCodeStore {
+	return newRecoveryCodes(ds.db)
+}
+
+// LoginChallenges 返回一个实现了 LoginChallengeStore 接口的实例.
+func (ds *datastore) LoginChallenges() LoginChallengeStore {
+	return newLoginChallenges(ds.db)
+}
+
+// DB 返回存储在 datastore 中的 *gorm.DB.
 func (ds *datastore) DB() *gorm.DB {
 	return ds.db
 }
+
+// TX 在一个数据库事务中执行 fn，fn 中需要使用传入的 ds 访问数据库.
+// fn 返回错误时事务回滚，否则提交.
+func (ds *datastore) TX(ctx context.Context, fn func(ds IStore) error) error {
+	return ds.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&datastore{tx})
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// TagStore 定义了 tag 模块在 store 层所实现的方法.
+type TagStore interface {
+	Ensure(ctx context.Context, tags []*model.TagM) ([]*model.TagM, error)
+	SetPostTags(ctx context.Context, postID string, tagIDs []int64) error
+	ListByPosts(ctx context.Context, postIDs []string) (map[string][]*model.TagM, error)
+	List(ctx context.Context, prefix string, limit int) ([]*TagCount, error)
+	DeletePostTags(ctx context.Context, postIDs []string) error
+}
+
+// TagCount 是标签及使用该标签的已发布公开博客数.
+type TagCount struct {
+	model.TagM
+	PostCount int64 `gorm:"column:postCount"`
+}
+
+// TagStore 接口的实现.
+type tags struct {
+	db *gorm.DB
+}
+
+// 确保 tags 实现了 TagStore 接口.
+var _ TagStore = (*tags)(nil)
+
+func newTags(db *gorm.DB) *tags {
+	return &tags{db}
+}
+
+// Ensure 创建数据库中还不存在的标签，返回所有标签的数据库记录，返回顺序与 tags 无关.
+// 标签以 slug 去重，已经存在的标签保留原有的显示名.
+func (t *tags) Ensure(ctx context.Context, tags []*model.TagM) ([]*model.TagM, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	err := t.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 忽略的记录不会回填 ID，需要重新查询
+	slugs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		slugs = append(slugs, tag.Slug)
+	}
+
+	var ret []*model.TagM
+	if err := t.db.Where("slug in (?)", slugs).Find(&ret).Error; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SetPostTags 将博客的标签替换为 tagIDs.
+func (t *tags) SetPostTags(ctx context.Context, postID string, tagIDs []int64) error {
+	if err := t.db.Where("postID = ?", postID).Delete(&model.PostTagM{}).Error; err != nil {
+		return err
+	}
+
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	postTags := make([]*model.PostTagM, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		postTags = append(postTags, &model.PostTagM{PostID: postID, TagID: id})
+	}
+
+	return t.db.Create(&postTags).Error
+}
+
+// ListByPosts 返回指定博客的标签，以 postID 为键，每篇博客的标签按添加顺序排列.
+func (t *tags) ListByPosts(ctx context.Context, postIDs []string) (map[string][]*model.TagM, error) {
+	var rows []*struct {
+		PostID string `gorm:"column:postID"`
+		model.TagM
+	}
+	err := t.db.Table("post_tag").
+		Select("post_tag.postID, tag.id, tag.slug, tag.name, tag.createdAt").
+		Joins("JOIN tag ON tag.id = post_tag.tagID").
+		Where("post_tag.postID in (?)", postIDs).
+		Order("post_tag.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string][]*model.TagM, len(postIDs))
+	for _, row := range rows {
+		tag := row.TagM
+		ret[row.PostID] = append(ret[row.PostID], &tag)
+	}
+
+	return ret, nil
+}
+
+// List 按使用次数倒序返回最多 limit 个标签，只统计已发布的公开博客，没有被这些博客使用的标签不会返回.
+// prefix 不为空时只返回 slug 以 prefix 开头的标签.
+func (t *tags) List(ctx context.Context, prefix string, limit int) ([]*TagCount, error) {
+	db := t.db.Table("tag").
+		Select("tag.id, tag.slug, tag.name, tag.createdAt, COUNT(*) AS postCount").
+		Joins("JOIN post_tag ON post_tag.tagID = tag.id").
+		Joins("JOIN post ON post.postID = post_tag.postID").
+		Where("post.status = ? AND post.visibility = ?", model.PostStatusPublished, model.PostVisibilityPublic)
+	if prefix != "" {
+		db = db.Where("tag.slug LIKE ?", prefixPattern(prefix))
+	}
+
+	var ret []*TagCount
+	err := db.Group("tag.id").Order("postCount desc, tag.slug").Limit(limit).Scan(&ret).Error
+
+	return ret, err
+}
+
+// DeletePostTags 删除指定博客与标签的关联，标签本身保留.
+func (t *tags) DeletePostTags(ctx context.Context, postIDs []string) error {
+	return t.db.Where("postID in (?)", postIDs).Delete(&model.PostTagM{}).Error
+}
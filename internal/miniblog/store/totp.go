@@ -0,0 +1,127 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// TOTPStore 定义了 totp 模块在 store 层所实现的方法.
+type TOTPStore interface {
+	Create(ctx context.Context, totp *model.TOTPM) error
+	Get(ctx context.Context, username string) (*model.TOTPM, error)
+	Enable(ctx context.Context, id, counter int64, now time.Time) (bool, error)
+	UseCounter(ctx context.Context, id, counter int64) (bool, error)
+	Attempt(ctx context.Context, id int64, now time.Time, max int, lockout time.Duration) (bool, error)
+	ResetAttempts(ctx context.Context, id int64) error
+	Delete(ctx context.Context, username string) error
+	DeletePending(ctx context.Context, username string) error
+}
+
+// TOTPStore 接口的实现.
+type totps struct {
+	db *gorm.DB
+}
+
+// 确保 totps 实现了 TOTPStore 接口.
+var _ TOTPStore = (*totps)(nil)
+
+func newTOTPs(db *gorm.DB) *totps {
+	return &totps{db}
+}
+
+// Create 插入一条 totp 记录.
+func (t *totps) Create(ctx context.Context, totp *model.TOTPM) error {
+	return t.db.Create(totp).Error
+}
+
+// Get 根据用户名查询 totp 记录.
+func (t *totps) Get(ctx context.Context, username string) (*model.TOTPM, error) {
+	var totp model.TOTPM
+	if err := t.db.Where("username = ?", username).First(&totp).Error; err != nil {
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// Enable 启用两步验证并记录验证成功的时间步长，已经启用时返回 false.
+func (t *totps) Enable(ctx context.Context, id, counter int64, now time.Time) (bool, error) {
+	result := t.db.Model(&model.TOTPM{}).
+		Where("id = ? AND enabledAt IS NULL", id).
+		UpdateColumns(map[string]interface{}{"enabledAt": now, "lastCounter": counter})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// UseCounter 将上一次验证成功的时间步长更新为 counter，counter 不大于记录中的时间步长时返回 false.
+// 同一个一次性密码被同时使用多次时，只有一个请求可以更新成功.
+func (t *totps) UseCounter(ctx context.Context, id, counter int64) (bool, error) {
+	result := t.db.Model(&model.TOTPM{}).
+		Where("id = ? AND lastCounter < ?", id, counter).
+		UpdateColumn("lastCounter", counter)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Attempt 在验证一次性密码或者恢复码之前为用户预留一次验证次数，用户被锁定时返回 false.
+// 预留的次数在验证成功之后由 ResetAttempts 清零，所以 failedAttempts 是连续失败的次数.
+// 次数达到 max 之后锁定到 now+lockout，锁定过期之后重新计数. 检查和累加在同一条 UPDATE 语句中完成，
+// 并发请求的验证次数也不会超过 max.
+func (t *totps) Attempt(ctx context.Context, id int64, now time.Time, max int, lockout time.Duration) (bool, error) {
+	// 锁定已经过期，重新计数
+	err := t.db.Model(&model.TOTPM{}).
+		Where("id = ? AND lockedUntil <= ?", id, now).
+		UpdateColumns(map[string]interface{}{"failedAttempts": 0, "lockedUntil": nil}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := t.db.Model(&model.TOTPM{}).
+		Where("id = ? AND lockedUntil IS NULL AND failedAttempts < ?", id, max).
+		UpdateColumn("failedAttempts", gorm.Expr("failedAttempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// 验证次数已经达到上限，开始锁定，已经锁定时不会延长锁定时间
+	err = t.db.Model(&model.TOTPM{}).
+		Where("id = ? AND lockedUntil IS NULL", id).
+		UpdateColumn("lockedUntil", now.Add(lockout)).Error
+
+	return false, err
+}
+
+// ResetAttempts 在验证成功之后清零用户连续失败的次数.
+func (t *totps) ResetAttempts(ctx context.Context, id int64) error {
+	return t.db.Model(&model.TOTPM{}).
+		Where("id = ? AND lockedUntil IS NULL", id).
+		UpdateColumn("failedAttempts", 0).Error
+}
+
+// Delete 删除用户的 totp 记录.
+func (t *totps) Delete(ctx context.Context, username string) error {
+	return t.db.Where("username = ?", username).Delete(&model.TOTPM{}).Error
+}
+
+// DeletePending 删除用户还没有完成绑定的 totp 记录，已经启用的记录不会被删除.
+func (t *totps) DeletePending(ctx context.Context, username string) error {
+	return t.db.Where("username = ? AND enabledAt IS NULL", username).Delete(&model.TOTPM{}).Error
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// UploadStore 定义了 upload 模块在 store 层所实现的方法.
+type UploadStore interface {
+	Create(ctx context.Context, upload *model.UploadM) (bool, error)
+	Get(ctx context.Context, username, hash string) (*model.UploadM, error)
+	GetByHash(ctx context.Context, hash string) (*model.UploadM, error)
+	TotalSize(ctx context.Context, username string) (int64, error)
+}
+
+// UploadStore 接口的实现.
+type uploads struct {
+	db *gorm.DB
+}
+
+// 确保 uploads 实现了 UploadStore 接口.
+var _ UploadStore = (*uploads)(nil)
+
+func newUploads(db *gorm.DB) *uploads {
+	return &uploads{db}
+}
+
+// Create 插入一条 upload 记录，用户已经上传过相同的文件时忽略，返回是否插入了新记录.
+func (u *uploads) Create(ctx context.Context, upload *model.UploadM) (bool, error) {
+	result := u.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}, {Name: "hash"}},
+		DoNothing: true,
+	}).Create(upload)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// Get 根据用户名和文件摘要查询 upload 记录.
+func (u *uploads) Get(ctx context.Context, username, hash string) (*model.UploadM, error) {
+	var upload model.UploadM
+	if err := u.db.Where("username = ? AND hash = ?", username, hash).First(&upload).Error; err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// GetByHash 返回最早上传 hash 对应文件的 upload 记录.
+func (u *uploads) GetByHash(ctx context.Context, hash string) (*model.UploadM, error) {
+	var upload model.UploadM
+	if err := u.db.Where("hash = ?", hash).Order("id").First(&upload).Error; err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// TotalSize 返回用户上传的全部文件的总字节数.
+func (u *uploads) TotalSize(ctx context.Context, username string) (int64, error) {
+	var total int64
+	err := u.db.Model(&model.UploadM{}).Where("username = ?", username).Select("COALESCE(SUM(size), 0)").Scan(&total).Error
+
+	return total, err
+}
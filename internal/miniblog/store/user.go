@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/ischeng28/miniblog/internal/pkg/model"
 )
@@ -18,6 +19,8 @@ type UserStore interface {
 	Create(ctx context.Context, user *model.UserM) error
 	Update(ctx context.Context, user *model.UserM) error
 	Get(ctx context.Context, username string) (*model.UserM, error)
+	IncrPostCount(ctx context.Context, username string, delta int64) error
+	Lock(ctx context.Context, username string) error
 }
 
 // UserStore 接口的实现.
@@ -38,8 +41,9 @@ func (u *users) Create(ctx context.Context, user *model.UserM) error {
 }
 
 // Update 更新一条数据库记录
+// postCount 由 IncrPostCount 原子维护，这里不覆盖它，避免用旧值覆盖并发更新的计数.
 func (u *users) Update(ctx context.Context, user *model.UserM) error {
-	return u.db.Save(user).Error
+	return u.db.Omit("postCount").Save(user).Error
 }
 
 func (u *users) Get(ctx context.Context, username string) (*model.UserM, error) {
@@ -49,3 +53,15 @@ func (u *users) Get(ctx context.Context, username string) (*model.UserM, error)
 	}
 	return &user, nil
 }
+
+// IncrPostCount 原子地将用户的 postCount 增加 delta，delta 可以为负数.
+func (u *users) IncrPostCount(ctx context.Context, username string, delta int64) error {
+	return u.db.Model(&model.UserM{}).Where("username = ?", username).
+		UpdateColumn("postCount", gorm.Expr("postCount + ?", delta)).Error
+}
+
+// Lock 锁定用户记录直到事务结束，用来串行化同一个用户的并发修改，只能在事务中调用.
+func (u *users) Lock(ctx context.Context, username string) error {
+	return u.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("username = ?", username).First(&model.UserM{}).Error
+}
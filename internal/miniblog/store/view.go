@@ -0,0 +1,83 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ischeng28/miniblog/internal/pkg/model"
+)
+
+// ViewStore 定义了博客浏览次数在 store 层所实现的方法.
+type ViewStore interface {
+	Incr(ctx context.Context, postID string, day time.Time, delta int64) (bool, error)
+	ListDaily(ctx context.Context, postID string, since time.Time) ([]*model.DailyViewM, error)
+	ListSince(ctx context.Context, since time.Time) ([]*model.DailyViewM, error)
+	DeleteByPosts(ctx context.Context, postIDs []string) error
+}
+
+// DailyCount 是按博客和日期分组统计的行为次数.
+type DailyCount struct {
+	PostID string    `gorm:"column:postID"`
+	Day    time.Time `gorm:"column:day"`
+	Count  int64     `gorm:"column:count"`
+}
+
+// ViewStore 接口的实现.
+type views struct {
+	db *gorm.DB
+}
+
+// 确保 views 实现了 ViewStore 接口.
+var _ ViewStore = (*views)(nil)
+
+func newViews(db *gorm.DB) *views {
+	return &views{db}
+}
+
+// Incr 将博客的总浏览次数和 day 当天的浏览次数增加 delta，博客不存在时返回 false.
+func (v *views) Incr(ctx context.Context, postID string, day time.Time, delta int64) (bool, error) {
+	// 显式设置 updatedAt，避免 MySQL 的 ON UPDATE CURRENT_TIMESTAMP 把浏览当作博客的修改
+	result := v.db.Model(&model.PostM{}).Where("postID = ?", postID).UpdateColumns(map[string]interface{}{
+		"viewCount": gorm.Expr("viewCount + ?", delta),
+		"updatedAt": gorm.Expr("updatedAt"),
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	err := v.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "postID"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("count + ?", delta)}),
+	}).Create(&model.DailyViewM{PostID: postID, Day: day, Count: delta}).Error
+
+	return err == nil, err
+}
+
+// ListDaily 按日期顺序返回博客从 since 当天开始每天的浏览次数，没有浏览的日期不会返回.
+func (v *views) ListDaily(ctx context.Context, postID string, since time.Time) ([]*model.DailyViewM, error) {
+	var ret []*model.DailyViewM
+	err := v.db.Where("postID = ? AND day >= ?", postID, since).Order("day").Find(&ret).Error
+
+	return ret, err
+}
+
+// ListSince 返回所有博客从 since 当天开始每天的浏览次数.
+func (v *views) ListSince(ctx context.Context, since time.Time) ([]*model.DailyViewM, error) {
+	var ret []*model.DailyViewM
+	err := v.db.Where("day >= ?", since).Find(&ret).Error
+
+	return ret, err
+}
+
+// DeleteByPosts 删除博客的每日浏览记录.
+func (v *views) DeleteByPosts(ctx context.Context, postIDs []string) error {
+	return v.db.Where("postID IN ?", postIDs).Delete(&model.DailyViewM{}).Error
+}
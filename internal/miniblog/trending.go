@@ -0,0 +1,68 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/miniblog/trending"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+const (
+	// defaultTrendingInterval 是未配置 trending.interval 时重新计算热门博客排行的间隔.
+	defaultTrendingInterval = 5 * time.Minute
+	// defaultTrendingWindow 是未配置 trending.window 时统计的时间范围.
+	defaultTrendingWindow = 7 * 24 * time.Hour
+	// defaultTrendingHalfLife 是未配置 trending.half-life 时分数的半衰期.
+	defaultTrendingHalfLife = 48 * time.Hour
+	// defaultTrendingSize 是未配置 trending.size 时排行中最多保存的博客数.
+	defaultTrendingSize = 100
+)
+
+// startTrending 立即计算一次热门博客排行，之后在后台定期重新计算，ctx 被取消时退出.
+func startTrending(ctx context.Context) {
+	interval := viper.GetDuration("trending.interval")
+	if interval <= 0 {
+		interval = defaultTrendingInterval
+	}
+
+	opts := trendingOptions()
+	log.Infow("Start trending posts ranker", "interval", interval, "window", opts.Window, "halfLife", opts.HalfLife)
+
+	ranking := trending.NewRanking()
+	rank := func() {
+		entries, err := biz.NewBiz(store.S).Posts().RankTrending(ctx, opts)
+		if err != nil {
+			log.Errorw("Failed to rank trending posts", "err", err)
+			return
+		}
+
+		ranking.Set(entries)
+	}
+
+	go func() {
+		rank()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			rank()
+		}
+	}()
+}
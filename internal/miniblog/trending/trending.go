@@ -0,0 +1,59 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package trending 保存定期计算的热门博客排行，请求时直接读取，不需要每次计算.
+package trending
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	once sync.Once
+	// S 全局变量，方便其它包直接调用已初始化好的 S 实例.
+	S *Ranking
+)
+
+// Entry 是排行中的一篇博客.
+type Entry struct {
+	PostID string
+	Score  float64
+}
+
+// Ranking 保存最近一次计算的热门博客排行，可以被多个 goroutine 同时访问.
+type Ranking struct {
+	mu        sync.RWMutex
+	entries   []Entry
+	updatedAt time.Time
+}
+
+// NewRanking 创建一个空的 Ranking 并设置全局变量 S.
+func NewRanking() *Ranking {
+	once.Do(func() {
+		S = &Ranking{}
+	})
+
+	return S
+}
+
+// Set 替换排行，entries 需要按分数从高到低排列.
+func (r *Ranking) Set(entries []Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = entries
+	r.updatedAt = time.Now()
+}
+
+// Top 返回排行中的前 n 篇博客以及排行的计算时间，排行还没有计算过时计算时间为零值.
+func (r *Ranking) Top(n int) ([]Entry, time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n = min(n, len(r.entries))
+
+	return r.entries[:n:n], r.updatedAt
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/biz"
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+const (
+	// defaultUploadGCInterval 是未配置 upload.gc-interval 时清理未被引用的上传文件的间隔.
+	defaultUploadGCInterval = time.Hour
+	// defaultUploadGCGrace 是未配置 upload.gc-grace 时未被引用的上传文件至少保留的时间.
+	defaultUploadGCGrace = 24 * time.Hour
+)
+
+// startUploadCollector 在后台定期删除没有被 upload 记录引用的文件，ctx 被取消时退出.
+// 写入记录失败的上传会留下文件，文件以内容命名，可能被其他用户同时上传，所以只删除保存超过 upload.gc-grace 的文件.
+func startUploadCollector(ctx context.Context) {
+	interval := viper.GetDuration("upload.gc-interval")
+	if interval <= 0 {
+		interval = defaultUploadGCInterval
+	}
+	grace := viper.GetDuration("upload.gc-grace")
+	if grace <= 0 {
+		grace = defaultUploadGCGrace
+	}
+
+	log.Infow("Start upload collector", "interval", interval, "grace", grace)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			n, err := biz.NewBiz(store.S).Uploads().Collect(ctx, grace)
+			if err != nil {
+				log.Errorw("Failed to collect unreferenced uploads", "err", err)
+				continue
+			}
+			if n > 0 {
+				log.Infow("Collected unreferenced uploads", "count", n)
+			}
+		}
+	}()
+}
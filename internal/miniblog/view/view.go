@@ -0,0 +1,129 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package view 在内存中累计博客的浏览次数，并定期批量写入数据库，避免每次浏览都写数据库.
+package view
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+var (
+	once sync.Once
+	// S 全局变量，方便其它包直接调用已初始化好的 S 实例.
+	S *Counter
+)
+
+// key 标识一篇博客在某一天的浏览次数.
+type key struct {
+	postID string
+	day    time.Time
+}
+
+// Counter 在内存中累计博客的浏览次数.
+// 累计的浏览次数由 Run 定期写入数据库，待写入的博客数达到 batchSize 时会提前写入.
+type Counter struct {
+	ds        store.IStore
+	batchSize int
+
+	mu      sync.Mutex
+	pending map[key]int64
+	// full 用来通知 Run 待写入的数据已经达到 batchSize.
+	full chan struct{}
+	// flushMu 保证同一时间只有一次写入，避免 Run 和关闭服务时的写入交错.
+	flushMu sync.Mutex
+}
+
+// NewCounter 创建一个 Counter 并设置全局变量 S.
+func NewCounter(ds store.IStore, batchSize int) *Counter {
+	once.Do(func() {
+		S = &Counter{ds: ds, batchSize: batchSize, pending: make(map[key]int64), full: make(chan struct{}, 1)}
+	})
+
+	return S
+}
+
+// Record 记录一次博客浏览.
+func (c *Counter) Record(postID string) {
+	now := time.Now()
+	k := key{postID: postID, day: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())}
+
+	c.mu.Lock()
+	c.pending[k]++
+	n := len(c.pending)
+	c.mu.Unlock()
+
+	if n >= c.batchSize {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run 每隔 interval 将累计的浏览次数写入数据库，直到 ctx 被取消.
+// Run 退出时不会写入剩余的浏览次数，关闭服务时需要在 HTTP 服务器停止后调用 Flush.
+func (c *Counter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.full:
+		}
+
+		if err := c.Flush(ctx); err != nil {
+			log.Errorw("Failed to flush post views", "err", err)
+		}
+	}
+}
+
+// Flush 将累计的浏览次数在一个事务中写入数据库.
+// 写入失败时这批浏览次数会放回内存，下次写入时重试.
+func (c *Counter) Flush(ctx context.Context) error {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[key]int64, len(batch))
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := c.ds.TX(ctx, func(ds store.IStore) error {
+		for k, n := range batch {
+			// 博客已经被删除时忽略它的浏览次数
+			if _, err := ds.Views().Incr(ctx, k.postID, k.day, n); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.mu.Lock()
+		for k, n := range batch {
+			c.pending[k] += n
+		}
+		c.mu.Unlock()
+
+		return err
+	}
+
+	log.Debugw("Flushed post views", "posts", len(batch))
+
+	return nil
+}
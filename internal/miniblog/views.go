@@ -0,0 +1,59 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package miniblog
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ischeng28/miniblog/internal/miniblog/store"
+	"github.com/ischeng28/miniblog/internal/miniblog/view"
+	"github.com/ischeng28/miniblog/internal/pkg/log"
+)
+
+const (
+	// defaultViewFlushInterval 是未配置 view.flush-interval 时写入浏览次数的间隔.
+	defaultViewFlushInterval = 10 * time.Second
+	// defaultViewBatchSize 是未配置 view.batch-size 时触发提前写入的博客数.
+	defaultViewBatchSize = 1000
+	// viewFlushTimeout 是关闭服务时写入剩余浏览次数的超时时间.
+	viewFlushTimeout = 10 * time.Second
+)
+
+// startViewCounter 创建全局的浏览次数计数器，并在后台定期将浏览次数写入数据库，ctx 被取消时退出.
+func startViewCounter(ctx context.Context) *view.Counter {
+	interval := viper.GetDuration("view.flush-interval")
+	if interval <= 0 {
+		interval = defaultViewFlushInterval
+	}
+
+	batchSize := viper.GetInt("view.batch-size")
+	if batchSize <= 0 {
+		batchSize = defaultViewBatchSize
+	}
+
+	log.Infow("Start post view counter", "interval", interval, "batchSize", batchSize)
+
+	counter := view.NewCounter(store.S, batchSize)
+	go counter.Run(ctx, interval)
+
+	return counter
+}
+
+// flushViews 写入内存中剩余的浏览次数，需要在 HTTP 服务器停止接收请求之后调用，避免丢失浏览次数.
+func flushViews(counter *view.Counter) {
+	ctx, cancel := context.WithTimeout(context.Background(), viewFlushTimeout)
+	defer cancel()
+
+	if err := counter.Flush(ctx); err != nil {
+		log.Errorw("Failed to flush post views on shutdown", "err", err)
+		return
+	}
+
+	log.Infow("Flushed post views on shutdown")
+}
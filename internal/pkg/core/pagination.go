@@ -0,0 +1,112 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+)
+
+const (
+	// defaultPageLimit 是未指定 limit 时每页返回的记录数.
+	defaultPageLimit = 20
+	// maxPageLimit 是每页最多返回的记录数.
+	maxPageLimit = 100
+)
+
+// Cursor 定义了基于 (createdAt, id) 的分页游标，记录上一页最后一条记录的位置.
+// 相比 offset 分页，游标分页不会因为新插入的记录而跳过或重复返回数据，并且可以利用索引快速定位.
+// 按其它时间字段排序的列表（例如按发布时间排序的时间线）使用 CreatedAt 保存该时间字段.
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        int64     `json:"i"`
+}
+
+// Encode 将游标编码成对客户端不透明的字符串.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析客户端传入的游标字符串.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errno.ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errno.ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// Page 定义了一次游标分页查询的参数.
+type Page struct {
+	// Cursor 为 nil 时从第一页开始查询.
+	Cursor *Cursor
+	Limit  int
+}
+
+// NewPage 根据客户端传入的 cursor 和 limit 创建 Page，limit 为 0 时使用默认值.
+func NewPage(cursor string, limit int) (*Page, error) {
+	if limit < 0 || limit > maxPageLimit {
+		return nil, errno.ErrInvalidPageLimit
+	}
+
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+
+	page := &Page{Limit: limit}
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		page.Cursor = c
+	}
+
+	return page, nil
+}
+
+// Paginate 按照 (createdAt, id) 倒序执行游标分页查询，返回当前页的记录以及下一页的游标，
+// 没有更多记录时下一页游标为空字符串.
+// table 用于在联表查询时限定 createdAt 和 id 所属的表，单表查询时传空字符串即可.
+// cursorOf 用来从记录中取出游标字段.
+func Paginate[T any](db *gorm.DB, page *Page, table string, cursorOf func(T) Cursor) ([]T, string, error) {
+	createdAt, id := "createdAt", "id"
+	if table != "" {
+		createdAt, id = table+".createdAt", table+".id"
+	}
+
+	if page.Cursor != nil {
+		db = db.Where(createdAt+" < ? OR ("+createdAt+" = ? AND "+id+" < ?)",
+			page.Cursor.CreatedAt, page.Cursor.CreatedAt, page.Cursor.ID)
+	}
+
+	// 多查询一条记录，用来判断是否还有下一页
+	var items []T
+	if err := db.Order(createdAt + " desc").Order(id + " desc").Limit(page.Limit + 1).Find(&items).Error; err != nil {
+		return nil, "", err
+	}
+
+	if len(items) <= page.Limit {
+		return items, "", nil
+	}
+
+	items = items[:page.Limit]
+
+	return items, cursorOf(items[len(items)-1]).Encode(), nil
+}
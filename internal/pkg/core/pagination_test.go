@@ -0,0 +1,89 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ischeng28/miniblog/internal/pkg/errno"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []Cursor{
+		{},
+		{CreatedAt: time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC), ID: 42},
+		// 游标需要保留纳秒，否则同一秒内创建的记录会被跳过或者重复返回
+		{CreatedAt: time.Date(2024, 5, 1, 12, 30, 45, 123456789, time.FixedZone("CST", 8*3600)), ID: 1<<63 - 1},
+	}
+
+	for _, want := range tests {
+		s := want.Encode()
+		got, err := DecodeCursor(s)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) returned error: %v", s, err)
+		}
+		if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+			t.Errorf("DecodeCursor(Encode(%+v)) = %+v", want, *got)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsTampered(t *testing.T) {
+	valid := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC), ID: 42}.Encode()
+	flipped := []byte(valid)
+	flipped[0] ^= 0x01
+
+	tests := map[string]string{
+		"not base64":           "!!!",
+		"padded base64":        base64.URLEncoding.EncodeToString([]byte(`{"c":"2024-05-01T12:30:45Z","i":42}`)),
+		"truncated":            valid[:len(valid)/2],
+		"flipped byte":         string(flipped),
+		"not json":             base64.RawURLEncoding.EncodeToString([]byte("42")),
+		"wrong id type":        base64.RawURLEncoding.EncodeToString([]byte(`{"c":"2024-05-01T12:30:45Z","i":"42"}`)),
+		"invalid created time": base64.RawURLEncoding.EncodeToString([]byte(`{"c":"yesterday","i":42}`)),
+	}
+
+	for name, s := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeCursor(s); !errors.Is(err, errno.ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) error = %v, want %v", s, err, errno.ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC), ID: 42}
+
+	page, err := NewPage("", 0)
+	if err != nil {
+		t.Fatalf("NewPage() returned error: %v", err)
+	}
+	if page.Limit != defaultPageLimit || page.Cursor != nil {
+		t.Errorf("NewPage(\"\", 0) = %+v, want default limit and no cursor", page)
+	}
+
+	page, err = NewPage(cursor.Encode(), maxPageLimit)
+	if err != nil {
+		t.Fatalf("NewPage() returned error: %v", err)
+	}
+	if page.Limit != maxPageLimit || page.Cursor == nil || page.Cursor.ID != cursor.ID || !page.Cursor.CreatedAt.Equal(cursor.CreatedAt) {
+		t.Errorf("NewPage() = %+v, want limit %d and cursor %+v", page, maxPageLimit, cursor)
+	}
+
+	for _, limit := range []int{-1, maxPageLimit + 1} {
+		if _, err := NewPage("", limit); !errors.Is(err, errno.ErrInvalidPageLimit) {
+			t.Errorf("NewPage(\"\", %d) error = %v, want %v", limit, err, errno.ErrInvalidPageLimit)
+		}
+	}
+
+	if _, err := NewPage("!!!", 10); !errors.Is(err, errno.ErrInvalidCursor) {
+		t.Errorf("NewPage(\"!!!\", 10) error = %v, want %v", err, errno.ErrInvalidCursor)
+	}
+}
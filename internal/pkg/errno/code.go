@@ -27,6 +27,24 @@ var (
 	// ErrTokenInvalid 表示JWT Token格式错误
 	ErrTokenInvalid = &Errno{HTTP: 401, Code: "AuthFailure.TokenInvalid", Message: "Token was invalid."}
 
+	// ErrRefreshTokenInvalid 表示刷新令牌不存在、已过期或者已经被撤销.
+	ErrRefreshTokenInvalid = &Errno{HTTP: 401, Code: "AuthFailure.RefreshTokenInvalid", Message: "Refresh token was invalid."}
+
+	// ErrRefreshTokenReused 表示已经轮换的刷新令牌被再次使用，同一次登录产生的所有令牌都已经被撤销.
+	ErrRefreshTokenReused = &Errno{HTTP: 401, Code: "AuthFailure.RefreshTokenReused", Message: "Refresh token was reused, please login again."}
+
 	// ErrUnauthorized 表示请求没有被授权.
 	ErrUnauthorized = &Errno{HTTP: 401, Code: "AuthFailure.Unauthorized", Message: "Unauthorized."}
+
+	// ErrInsufficientScope 表示 Token 没有访问该接口所需的 scope.
+	ErrInsufficientScope = &Errno{HTTP: 403, Code: "AuthFailure.InsufficientScope", Message: "Token does not have the required scope."}
+
+	// ErrAccessTokenNotAllowed 表示接口只允许通过登录获得的 Token 访问，不接受个人访问令牌.
+	ErrAccessTokenNotAllowed = &Errno{HTTP: 403, Code: "AuthFailure.AccessTokenNotAllowed", Message: "Personal access tokens cannot be used for this operation."}
+
+	// ErrInvalidCursor 表示分页游标格式错误.
+	ErrInvalidCursor = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidCursor", Message: "Pagination cursor was invalid."}
+
+	// ErrInvalidPageLimit 表示分页大小超出范围.
+	ErrInvalidPageLimit = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidPageLimit", Message: "Pagination limit must be between 0 and 100."}
 )
@@ -0,0 +1,14 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package errno
+
+var (
+	// ErrCommentNotFound 表示未找到指定的评论.
+	ErrCommentNotFound = &Errno{HTTP: 404, Code: "ResourceNotFound.CommentNotFound", Message: "Comment was not found."}
+
+	// ErrInvalidParentComment 表示回复的评论不存在或者不属于当前博客.
+	ErrInvalidParentComment = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidParentComment", Message: "Parent comment does not exist on this post."}
+)
@@ -0,0 +1,26 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package errno
+
+var (
+	// ErrPostNotFound 表示未找到指定的博客.
+	ErrPostNotFound = &Errno{HTTP: 404, Code: "ResourceNotFound.PostNotFound", Message: "Post was not found."}
+
+	// ErrPostRevisionConflict 表示博客在本次修改之前已经被其他人修改.
+	ErrPostRevisionConflict = &Errno{HTTP: 409, Code: "FailedOperation.PostRevisionConflict", Message: "Post was modified by someone else, reload it and try again."}
+
+	// ErrPostRevisionNotFound 表示未找到指定的博客修订记录.
+	ErrPostRevisionNotFound = &Errno{HTTP: 404, Code: "ResourceNotFound.PostRevisionNotFound", Message: "Post revision was not found."}
+
+	// ErrInvalidPublishAt 表示定时发布的时间不合法.
+	ErrInvalidPublishAt = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidPublishAt", Message: "Scheduled posts require a publishAt time in the future."}
+
+	// ErrInvalidReactionKind 表示不支持的回应类型.
+	ErrInvalidReactionKind = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidReactionKind", Message: "Reaction kind is not supported."}
+
+	// ErrInvalidTags 表示博客的标签不合法.
+	ErrInvalidTags = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidTags", Message: "A post can have at most 10 tags, each 1 to 32 characters with at least one letter or digit."}
+)
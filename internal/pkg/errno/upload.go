@@ -0,0 +1,26 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package errno
+
+var (
+	// ErrUploadFileMissing 表示上传请求中没有包含文件.
+	ErrUploadFileMissing = &Errno{HTTP: 400, Code: "InvalidParameter.UploadFileMissing", Message: "Upload requires a multipart form field named file."}
+
+	// ErrUploadTooLarge 表示上传的文件超过了单个文件的大小限制.
+	ErrUploadTooLarge = &Errno{HTTP: 413, Code: "InvalidParameter.UploadTooLarge", Message: "Uploaded file is too large."}
+
+	// ErrUploadQuotaExceeded 表示用户上传的文件总大小超过了配额.
+	ErrUploadQuotaExceeded = &Errno{HTTP: 403, Code: "LimitExceeded.UploadQuotaExceeded", Message: "Upload quota exceeded."}
+
+	// ErrUnsupportedMediaType 表示不允许上传该类型的文件.
+	ErrUnsupportedMediaType = &Errno{HTTP: 415, Code: "InvalidParameter.UnsupportedMediaType", Message: "File type is not allowed."}
+
+	// ErrInvalidImage 表示上传的图片无法解码或者尺寸过大.
+	ErrInvalidImage = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidImage", Message: "Image could not be decoded or its dimensions are too large."}
+
+	// ErrMediaNotFound 表示未找到指定的文件.
+	ErrMediaNotFound = &Errno{HTTP: 404, Code: "ResourceNotFound.MediaNotFound", Message: "Media was not found."}
+)
@@ -14,4 +14,34 @@ var (
 
 	// ErrPasswordIncorrect 表示密码不正确
 	ErrPasswordIncorrect = &Errno{HTTP: 401, Code: "InvalidParameter.PasswordIncorrect", Message: "Incorrect password."}
+
+	// ErrFollowSelf 表示用户试图关注自己.
+	ErrFollowSelf = &Errno{HTTP: 400, Code: "InvalidParameter.FollowSelf", Message: "Users cannot follow themselves."}
+
+	// ErrFollowNotFound 表示关注关系不存在.
+	ErrFollowNotFound = &Errno{HTTP: 404, Code: "ResourceNotFound.FollowNotFound", Message: "Follow was not found."}
+
+	// ErrAccessTokenNotFound 表示个人访问令牌不存在.
+	ErrAccessTokenNotFound = &Errno{HTTP: 404, Code: "ResourceNotFound.AccessTokenNotFound", Message: "Access token was not found."}
+
+	// ErrAccessTokenLimitExceeded 表示用户的个人访问令牌数量已经达到上限.
+	ErrAccessTokenLimitExceeded = &Errno{HTTP: 400, Code: "LimitExceeded.AccessTokenLimitExceeded", Message: "Too many access tokens, please delete unused tokens first."}
+
+	// ErrInvalidScope 表示请求的 scope 不存在或者超出了当前 Token 的 scope.
+	ErrInvalidScope = &Errno{HTTP: 400, Code: "InvalidParameter.InvalidScope", Message: "Scope was invalid."}
+
+	// ErrTOTPAlreadyEnabled 表示用户已经启用了两步验证.
+	ErrTOTPAlreadyEnabled = &Errno{HTTP: 400, Code: "FailedOperation.TOTPAlreadyEnabled", Message: "Two-factor authentication is already enabled."}
+
+	// ErrTOTPNotEnabled 表示用户没有开始绑定或者没有启用两步验证.
+	ErrTOTPNotEnabled = &Errno{HTTP: 400, Code: "FailedOperation.TOTPNotEnabled", Message: "Two-factor authentication is not enabled."}
+
+	// ErrTOTPCodeIncorrect 表示一次性密码或者恢复码不正确，或者已经被使用过.
+	ErrTOTPCodeIncorrect = &Errno{HTTP: 401, Code: "InvalidParameter.TOTPCodeIncorrect", Message: "Incorrect verification code."}
+
+	// ErrTOTPLocked 表示用户连续验证失败的次数过多，两步验证暂时被锁定.
+	ErrTOTPLocked = &Errno{HTTP: 429, Code: "AuthFailure.TOTPLocked", Message: "Too many failed verification attempts, please try again later."}
+
+	// ErrLoginChallengeInvalid 表示两步验证的挑战令牌不存在、已过期或者失败次数过多.
+	ErrLoginChallengeInvalid = &Errno{HTTP: 401, Code: "AuthFailure.LoginChallengeInvalid", Message: "Login challenge was invalid, please login again."}
 )
@@ -11,4 +11,31 @@ const (
 
 	//	XUsernameKey 用来定义Gin上下文的键，代表请求的所有者
 	XUsernameKey = "X-Username"
+
+	// XClaimsKey 用来定义 Gin 上下文中的键，代表请求所携带 Token 的 *token.Claims.
+	XClaimsKey = "X-Claims"
+
+	// XAccessTokenIDKey 用来定义 Gin 上下文中的键，代表请求所使用的个人访问令牌的 tokenID，使用 JWT Token 时不设置.
+	XAccessTokenIDKey = "X-Access-Token-ID"
+
+	// AccessTokenPrefix 是个人访问令牌的前缀，用来区分个人访问令牌和 JWT Token，也便于扫描泄露的令牌.
+	AccessTokenPrefix = "mbp_"
+)
+
+// RoleUser 是通过用户名和密码登录的普通用户的角色.
+const RoleUser = "user"
+
+// 以下是 Token 可以携带的 scope，读接口需要 ScopeRead，写接口需要对应资源的写 scope.
+const (
+	// ScopeRead 允许读取博客、评论、标签和用户信息.
+	ScopeRead = "read"
+	// ScopePostsWrite 允许创建、修改和删除博客以及上传文件.
+	ScopePostsWrite = "posts:write"
+	// ScopeCommentsWrite 允许发表、修改、删除和审核评论以及回应博客.
+	ScopeCommentsWrite = "comments:write"
+	// ScopeUsersWrite 允许修改用户信息和关注关系.
+	ScopeUsersWrite = "users:write"
 )
+
+// AllScopes 是通过用户名和密码登录时签发的 Token 所携带的 scope.
+var AllScopes = []string{ScopeRead, ScopePostsWrite, ScopeCommentsWrite, ScopeUsersWrite}
@@ -0,0 +1,30 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package known
+
+import "net/url"
+
+// 以下函数返回公开页面的路径，订阅源和 HTML 页面使用相同的路径生成链接.
+
+// BlogPath 返回用户博客首页的路径.
+func BlogPath(username string) string {
+	return "/users/" + url.PathEscape(username)
+}
+
+// PostPath 返回博客页面的路径.
+func PostPath(username, postID string) string {
+	return BlogPath(username) + "/posts/" + url.PathEscape(postID)
+}
+
+// TagPath 返回标签页面的路径.
+func TagPath(slug string) string {
+	return "/tags/" + url.PathEscape(slug)
+}
+
+// MediaPath 返回上传文件的访问路径.
+func MediaPath(hash string) string {
+	return "/media/" + url.PathEscape(hash)
+}
@@ -6,6 +6,10 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/ischeng28/miniblog/internal/pkg/core"
 	"github.com/ischeng28/miniblog/internal/pkg/errno"
@@ -13,16 +17,72 @@ import (
 	"github.com/ischeng28/miniblog/pkg/token"
 )
 
+// AccessTokenVerifier 用来定义个人访问令牌的验证接口
+type AccessTokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, value string) (*token.Claims, error)
+}
+
+// accessTokens 用来验证个人访问令牌，为 nil 时只接受jwt token
+var accessTokens AccessTokenVerifier
+
+// UseAccessTokens 设置Authn验证个人访问令牌所用的AccessTokenVerifier
+func UseAccessTokens(v AccessTokenVerifier) {
+	accessTokens = v
+}
+
+// Authn 是Gin中间件，用来解析jwt token或者个人访问令牌，并将用户名和完整的claims保存到Gin上下文中
 func Authn() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		//	 解析jwt token
-		username, err := token.ParseRequest(c)
+		var (
+			claims *token.Claims
+			err    error
+		)
+		if t, _ := token.FromRequest(c); accessTokens != nil && strings.HasPrefix(t, known.AccessTokenPrefix) {
+			//	 验证个人访问令牌
+			if claims, err = accessTokens.VerifyAccessToken(c, t); err == nil {
+				c.Set(known.XAccessTokenIDKey, claims.ID)
+			}
+		} else {
+			//	 解析jwt token
+			claims, err = token.ParseRequest(c)
+		}
 		if err != nil {
 			core.WriteResponse(c, errno.ErrTokenInvalid, nil)
 			c.Abort()
 			return
 		}
-		c.Set(known.XUsernameKey, username)
+		c.Set(known.XUsernameKey, claims.Subject)
+		c.Set(known.XClaimsKey, claims)
 		c.Next()
 	}
 }
+
+// Scope 是Gin中间件，用来检查token的scope，需要在Authn之后使用
+// GET和HEAD请求需要read scope，其它请求需要write scope
+func Scope(write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = known.ScopeRead
+		}
+
+		claims, ok := c.Value(known.XClaimsKey).(*token.Claims)
+		if !ok || !claims.HasScope(scope) {
+			core.WriteResponse(c, errno.ErrInsufficientScope, nil)
+			c.Abort()
+			return
+		}
+	}
+}
+
+// Interactive 是Gin中间件，拒绝使用个人访问令牌的请求，需要在Authn之后使用
+// 管理个人访问令牌和两步验证等敏感操作只允许通过登录获得的jwt token访问，避免泄露的个人访问令牌被用来扩大权限
+func Interactive() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(known.XAccessTokenIDKey) != "" {
+			core.WriteResponse(c, errno.ErrAccessTokenNotAllowed, nil)
+			c.Abort()
+			return
+		}
+	}
+}
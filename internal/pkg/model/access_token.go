@@ -0,0 +1,43 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/pkg/util/id"
+)
+
+// AccessTokenM 是数据库中 access_token 记录 struct 格式的映射.
+// 个人访问令牌用于自动化脚本等不方便使用密码登录的场景，只能访问创建时指定的 scope.
+type AccessTokenM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	TokenID  string `gorm:"column:tokenID;not null"`
+	Username string `gorm:"column:username;not null"`
+	Name     string `gorm:"column:name;not null"`
+	// Scopes 是令牌可以访问的 scope，以空格分隔.
+	Scopes string `gorm:"column:scopes;not null"`
+	// TokenHash 是令牌的 SHA-256 摘要，十六进制编码，数据库中不保存令牌本身.
+	TokenHash string    `gorm:"column:tokenHash;not null"`
+	ExpiresAt time.Time `gorm:"column:expiresAt;not null"`
+	// LastUsedAt 是令牌最后一次通过认证的时间，为空表示令牌从未被使用.
+	LastUsedAt *time.Time `gorm:"column:lastUsedAt"`
+	CreatedAt  time.Time  `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (t *AccessTokenM) TableName() string {
+	return "access_token"
+}
+
+// BeforeCreate 在创建数据库记录之前生成 tokenID.
+func (t *AccessTokenM) BeforeCreate(tx *gorm.DB) error {
+	t.TokenID = "token-" + id.GenShortID()
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/pkg/util/id"
+)
+
+// 评论的审核状态.
+const (
+	// CommentStatusPending 表示评论正在等待博客所有者审核，只有作者本人和博客所有者可以看到.
+	CommentStatusPending = "pending"
+	// CommentStatusApproved 表示评论已经公开.
+	CommentStatusApproved = "approved"
+	// CommentStatusRejected 表示评论没有通过审核.
+	CommentStatusRejected = "rejected"
+	// CommentStatusSpam 表示评论被标记为垃圾评论.
+	CommentStatusSpam = "spam"
+)
+
+// CommentM 是数据库中 comment 记录 struct 格式的映射.
+type CommentM struct {
+	ID        int64  `gorm:"column:id;primary_key"`
+	CommentID string `gorm:"column:commentID;not null"`
+	PostID    string `gorm:"column:postID;not null"`
+	// ParentID 是被回复的评论，为空表示直接评论博客.
+	ParentID string `gorm:"column:parentID;not null"`
+	// RootID 是评论所在讨论串的第一条评论，直接评论博客的评论 RootID 等于自身的 CommentID.
+	RootID   string `gorm:"column:rootID;not null"`
+	Username string `gorm:"column:username;not null"`
+	Content  string `gorm:"column:content;not null"`
+	Status   string `gorm:"column:status;not null"`
+	// Deleted 表示评论已经被删除，有回复的评论删除后保留一条没有内容的记录，以保持讨论串的结构.
+	Deleted   bool      `gorm:"column:deleted;not null"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+	UpdatedAt time.Time `gorm:"column:updatedAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (c *CommentM) TableName() string {
+	return "comment"
+}
+
+// BeforeCreate 在创建数据库记录之前生成 commentID.
+func (c *CommentM) BeforeCreate(tx *gorm.DB) error {
+	c.CommentID = "comment-" + id.GenShortID()
+	if c.RootID == "" {
+		c.RootID = c.CommentID
+	}
+
+	return nil
+}
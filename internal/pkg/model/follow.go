@@ -0,0 +1,23 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// FollowM 是数据库中 follow 记录 struct 格式的映射，表示 Follower 关注了 Followee.
+type FollowM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	Follower string `gorm:"column:follower;not null"`
+	Followee string `gorm:"column:followee;not null"`
+	// Approved 表示 Followee 批准了这次关注，只有批准的关注者可以看到 Followee 仅关注者可见的博客.
+	Approved  bool      `gorm:"column:approved;not null"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (f *FollowM) TableName() string {
+	return "follow"
+}
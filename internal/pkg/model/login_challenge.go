@@ -0,0 +1,26 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// LoginChallengeM 是数据库中 login_challenge 记录 struct 格式的映射.
+// 启用了两步验证的用户通过密码验证之后得到一个挑战令牌，使用挑战令牌和一次性密码换取 Token.
+type LoginChallengeM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	Username string `gorm:"column:username;not null"`
+	// TokenHash 是挑战令牌的 SHA-256 摘要，十六进制编码，数据库中不保存令牌本身.
+	TokenHash string `gorm:"column:tokenHash;not null"`
+	// Attempts 是使用挑战令牌验证一次性密码的次数，达到上限之后挑战令牌失效，防止暴力猜测一次性密码.
+	Attempts  int       `gorm:"column:attempts;not null"`
+	ExpiresAt time.Time `gorm:"column:expiresAt;not null"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (c *LoginChallengeM) TableName() string {
+	return "login_challenge"
+}
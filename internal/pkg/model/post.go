@@ -5,15 +5,59 @@
 
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ischeng28/miniblog/pkg/util/id"
+)
+
+// 博客的发布状态.
+const (
+	// PostStatusDraft 表示草稿，只有作者可见.
+	PostStatusDraft = "draft"
+	// PostStatusScheduled 表示定时发布，到达 PublishAt 之前只有作者可见.
+	PostStatusScheduled = "scheduled"
+	// PostStatusPublished 表示已发布.
+	PostStatusPublished = "published"
+)
+
+// 博客的可见范围，只对已发布的博客有意义，未发布的博客只有作者可见.
+const (
+	// PostVisibilityPublic 表示所有人可见，会出现在列表、订阅源和搜索结果中.
+	PostVisibilityPublic = "public"
+	// PostVisibilityUnlisted 表示知道链接的人可见，不会出现在任何列表中.
+	PostVisibilityUnlisted = "unlisted"
+	// PostVisibilityPrivate 表示只有作者可见.
+	PostVisibilityPrivate = "private"
+	// PostVisibilityFollowers 表示只有作者批准的关注者可见.
+	PostVisibilityFollowers = "followers"
+)
 
 // PostM 是数据库中 post 记录 struct 格式的映射.
 type PostM struct {
-	ID        int64     `gorm:"column:id;primary_key"`
-	Username  string    `gorm:"column:username;not null"`
-	PostID    string    `gorm:"column:postID;not null"`
-	Title     string    `gorm:"column:title;not null"`
-	Content   string    `gorm:"column:content"`
+	ID       int64  `gorm:"column:id;primary_key"`
+	Username string `gorm:"column:username;not null"`
+	PostID   string `gorm:"column:postID;not null"`
+	Title    string `gorm:"column:title;not null"`
+	Content  string `gorm:"column:content"`
+	// Revision 是博客当前的修订号，每次修改博客都会递增，同时用来做乐观锁.
+	Revision int    `gorm:"column:revision;not null"`
+	Status   string `gorm:"column:status;not null"`
+	// Visibility 是博客的可见范围.
+	Visibility string `gorm:"column:visibility;not null"`
+	// PublishAt 是定时发布的时间，只对 scheduled 状态的博客有意义.
+	PublishAt *time.Time `gorm:"column:publishAt"`
+	// PublishedAt 是博客实际发布的时间.
+	PublishedAt *time.Time `gorm:"column:publishedAt"`
+	// ClaimedBy 和 ClaimedAt 记录正在发布该博客的 miniblog 实例，防止多个实例重复发布.
+	ClaimedBy string     `gorm:"column:claimedBy;not null"`
+	ClaimedAt *time.Time `gorm:"column:claimedAt"`
+	// ModerateComments 表示博客的评论需要所有者审核通过后才会公开.
+	ModerateComments bool `gorm:"column:moderateComments;not null"`
+	// ViewCount 是博客的浏览次数，由 view.Counter 定期批量累加，不随博客的修改而更新.
+	ViewCount int64     `gorm:"column:viewCount;not null"`
 	CreatedAt time.Time `gorm:"column:createdAt"`
 	UpdatedAt time.Time `gorm:"column:updatedAt"`
 }
@@ -22,3 +66,39 @@ type PostM struct {
 func (p *PostM) TableName() string {
 	return "post"
 }
+
+// IsPublished 返回博客是否已经发布.
+func (p *PostM) IsPublished() bool {
+	return p.Status == PostStatusPublished
+}
+
+// IsPublic 返回博客是否已经发布并且所有人可见.
+func (p *PostM) IsPublic() bool {
+	return p.IsPublished() && p.Visibility == PostVisibilityPublic
+}
+
+// VisibleTo 返回 viewer 是否可以通过链接访问博客，approved 表示 viewer 是否是作者批准的关注者.
+func (p *PostM) VisibleTo(viewer string, approved bool) bool {
+	if viewer != "" && viewer == p.Username {
+		return true
+	}
+	if !p.IsPublished() {
+		return false
+	}
+
+	switch p.Visibility {
+	case PostVisibilityPublic, PostVisibilityUnlisted:
+		return true
+	case PostVisibilityFollowers:
+		return approved
+	default:
+		return false
+	}
+}
+
+// BeforeCreate 在创建数据库记录之前生成 postID.
+func (p *PostM) BeforeCreate(tx *gorm.DB) error {
+	p.PostID = "post-" + id.GenShortID()
+
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// PostRevisionM 是数据库中 post_revision 记录 struct 格式的映射.
+// 每次创建或修改博客都会保存一条不可变的修订记录.
+type PostRevisionM struct {
+	ID     int64  `gorm:"column:id;primary_key"`
+	PostID string `gorm:"column:postID;not null"`
+	// Revision 是修订号，同一篇博客的修订号从 1 开始递增.
+	Revision int `gorm:"column:revision;not null"`
+	// Username 是产生该修订的用户.
+	Username  string    `gorm:"column:username;not null"`
+	Title     string    `gorm:"column:title;not null"`
+	Content   string    `gorm:"column:content"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (r *PostRevisionM) TableName() string {
+	return "post_revision"
+}
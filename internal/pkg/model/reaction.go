@@ -0,0 +1,50 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// ReactionKinds 是支持的回应类型.
+var ReactionKinds = []string{"like", "love", "laugh", "celebrate", "insightful", "sad"}
+
+// IsReactionKind 返回 kind 是否是支持的回应类型.
+func IsReactionKind(kind string) bool {
+	for _, k := range ReactionKinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReactionM 是数据库中 post_reaction 记录 struct 格式的映射，每个用户对同一篇博客的每种回应只有一条记录.
+type ReactionM struct {
+	ID        int64     `gorm:"column:id;primary_key"`
+	PostID    string    `gorm:"column:postID;not null"`
+	Username  string    `gorm:"column:username;not null"`
+	Kind      string    `gorm:"column:kind;not null"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (r *ReactionM) TableName() string {
+	return "post_reaction"
+}
+
+// ReactionCountM 是数据库中 post_reaction_count 记录 struct 格式的映射，
+// 保存每篇博客每种回应的数量，和 post_reaction 在同一个事务中更新.
+type ReactionCountM struct {
+	ID     int64  `gorm:"column:id;primary_key"`
+	PostID string `gorm:"column:postID;not null"`
+	Kind   string `gorm:"column:kind;not null"`
+	Count  int64  `gorm:"column:count;not null"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (c *ReactionCountM) TableName() string {
+	return "post_reaction_count"
+}
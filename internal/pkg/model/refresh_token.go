@@ -0,0 +1,29 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// RefreshTokenM 是数据库中 refresh_token 记录 struct 格式的映射.
+// 每次登录生成一个令牌族，刷新令牌每次使用后都会轮换为同一个令牌族中的新令牌.
+type RefreshTokenM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	Username string `gorm:"column:username;not null"`
+	// Family 是令牌族的 ID，注销或者发现令牌被重复使用时撤销整个令牌族.
+	Family string `gorm:"column:family;not null"`
+	// TokenHash 是刷新令牌的 SHA-256 摘要，十六进制编码，数据库中不保存令牌本身.
+	TokenHash string    `gorm:"column:tokenHash;not null"`
+	ExpiresAt time.Time `gorm:"column:expiresAt;not null"`
+	// RotatedAt 是令牌被使用并轮换的时间，已经轮换的令牌再次被使用说明令牌可能已经泄露.
+	RotatedAt *time.Time `gorm:"column:rotatedAt"`
+	RevokedAt *time.Time `gorm:"column:revokedAt"`
+	CreatedAt time.Time  `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (t *RefreshTokenM) TableName() string {
+	return "refresh_token"
+}
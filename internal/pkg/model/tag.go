@@ -0,0 +1,36 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// TagM 是数据库中 tag 记录 struct 格式的映射.
+type TagM struct {
+	ID int64 `gorm:"column:id;primary_key"`
+	// Slug 是标签规范化后的名字，标签以 slug 去重.
+	Slug string `gorm:"column:slug;not null"`
+	// Name 是标签第一次被使用时的显示名.
+	Name      string    `gorm:"column:name;not null"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (t *TagM) TableName() string {
+	return "tag"
+}
+
+// PostTagM 是数据库中 post_tag 记录 struct 格式的映射，表示博客和标签之间的多对多关系.
+type PostTagM struct {
+	ID        int64     `gorm:"column:id;primary_key"`
+	PostID    string    `gorm:"column:postID;not null"`
+	TagID     int64     `gorm:"column:tagID;not null"`
+	CreatedAt time.Time `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (pt *PostTagM) TableName() string {
+	return "post_tag"
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// TOTPM 是数据库中 totp 记录 struct 格式的映射，每个用户最多有一条记录.
+// 用户开始绑定时生成密钥，使用身份验证器生成的密码验证通过之后才会启用两步验证.
+type TOTPM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	Username string `gorm:"column:username;not null"`
+	// Secret 是 base32 编码的密钥，验证一次性密码时需要使用原始密钥，所以不能只保存摘要.
+	Secret string `gorm:"column:secret;not null"`
+	// EnabledAt 为空表示用户还没有完成绑定，两步验证没有启用.
+	EnabledAt *time.Time `gorm:"column:enabledAt"`
+	// LastCounter 是上一次验证成功的时间步长，不大于它的一次性密码不能再次使用.
+	LastCounter int64 `gorm:"column:lastCounter;not null"`
+	// FailedAttempts 是上一次验证成功之后连续验证失败的次数，不会因为重新登录而清零.
+	FailedAttempts int `gorm:"column:failedAttempts;not null"`
+	// LockedUntil 不为空时，在该时间之前不再接受一次性密码和恢复码.
+	LockedUntil *time.Time `gorm:"column:lockedUntil"`
+	CreatedAt   time.Time  `gorm:"column:createdAt"`
+	UpdatedAt   time.Time  `gorm:"column:updatedAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (t *TOTPM) TableName() string {
+	return "totp"
+}
+
+// Enabled 返回用户是否已经启用了两步验证.
+func (t *TOTPM) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// RecoveryCodeM 是数据库中 recovery_code 记录 struct 格式的映射.
+// 恢复码在用户无法使用身份验证器时代替一次性密码，每个恢复码只能使用一次.
+type RecoveryCodeM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	Username string `gorm:"column:username;not null"`
+	// CodeHash 是恢复码的 SHA-256 摘要，十六进制编码，数据库中不保存恢复码本身.
+	CodeHash  string     `gorm:"column:codeHash;not null"`
+	UsedAt    *time.Time `gorm:"column:usedAt"`
+	CreatedAt time.Time  `gorm:"column:createdAt"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (c *RecoveryCodeM) TableName() string {
+	return "recovery_code"
+}
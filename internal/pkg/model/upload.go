@@ -0,0 +1,38 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// UploadM 是数据库中 upload 记录 struct 格式的映射.
+// 文件内容按照 Hash 保存在 BlobStore 中，多个用户上传相同的文件时共享同一份内容，
+// 但每个用户各有一条记录，用来统计用户已经使用的空间.
+type UploadM struct {
+	ID       int64  `gorm:"column:id;primary_key"`
+	Username string `gorm:"column:username;not null"`
+	// Hash 是文件内容的 SHA-256 摘要，十六进制编码.
+	Hash        string `gorm:"column:hash;not null"`
+	Filename    string `gorm:"column:filename;not null"`
+	ContentType string `gorm:"column:contentType;not null"`
+	Size        int64  `gorm:"column:size;not null"`
+	// Width 和 Height 是图片的尺寸，其它类型的文件为 0.
+	Width  int `gorm:"column:width;not null"`
+	Height int `gorm:"column:height;not null"`
+	// Variants 是图片的缩略图，按宽度从小到大排列.
+	Variants  []UploadVariant `gorm:"column:variants;serializer:json"`
+	CreatedAt time.Time       `gorm:"column:createdAt"`
+}
+
+// UploadVariant 是图片的一个缩略图，保存在 BlobStore 中的 key 为 `<hash>-<width>`.
+type UploadVariant struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (u *UploadM) TableName() string {
+	return "upload"
+}
@@ -20,6 +20,7 @@ type UserM struct {
 	Nickname  string    `gorm:"column:nickname"`
 	Email     string    `gorm:"column:email"`
 	Phone     string    `gorm:"column:phone"`
+	PostCount int64     `gorm:"column:postCount"`
 	CreatedAt time.Time `gorm:"column:createdAt"`
 	UpdatedAt time.Time `gorm:"column:updatedAt"`
 }
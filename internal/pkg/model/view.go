@@ -0,0 +1,21 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package model
+
+import "time"
+
+// DailyViewM 是数据库中 post_daily_view 记录 struct 格式的映射，记录博客每天的浏览次数.
+type DailyViewM struct {
+	ID     int64     `gorm:"column:id;primary_key"`
+	PostID string    `gorm:"column:postID;not null"`
+	Day    time.Time `gorm:"column:day;type:date;not null"`
+	Count  int64     `gorm:"column:count;not null"`
+}
+
+// TableName 用来指定映射的 MySQL 表名.
+func (v *DailyViewM) TableName() string {
+	return "post_daily_view"
+}
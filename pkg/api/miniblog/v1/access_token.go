@@ -0,0 +1,41 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// CreateAccessTokenRequest 指定了 `POST /v1/users/{name}/tokens` 接口的请求参数.
+type CreateAccessTokenRequest struct {
+	// Name 用来区分不同用途的令牌，例如 "release-bot".
+	Name string `json:"name" valid:"required,stringlength(1|64)"`
+	// Scopes 是令牌可以访问的 scope，不能超出创建令牌所用 Token 的 scope.
+	Scopes []string `json:"scopes" valid:"required"`
+	// ExpiresInDays 是令牌的有效天数.
+	ExpiresInDays int `json:"expiresInDays" valid:"required,range(1|366)"`
+}
+
+// CreateAccessTokenResponse 指定了 `POST /v1/users/{name}/tokens` 接口的返回参数.
+type CreateAccessTokenResponse struct {
+	AccessTokenInfo
+	// Token 是令牌本身，只在创建时返回一次，服务端只保存令牌的摘要.
+	Token string `json:"token"`
+}
+
+// AccessTokenInfo 指定了个人访问令牌的详细信息，不包含令牌本身.
+type AccessTokenInfo struct {
+	TokenID   string   `json:"tokenID"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expiresAt"`
+	// LastUsedAt 为空表示令牌从未被使用.
+	LastUsedAt string `json:"lastUsedAt"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+// ListAccessTokenResponse 指定了 `GET /v1/users/{name}/tokens` 接口的返回参数.
+type ListAccessTokenResponse struct {
+	TotalCount int64 `json:"totalCount"`
+	// AccessTokens 按创建时间倒序排列.
+	AccessTokens []*AccessTokenInfo `json:"accessTokens"`
+}
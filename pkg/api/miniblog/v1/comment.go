@@ -0,0 +1,60 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// CreateCommentRequest 指定了 `POST /v1/posts/{postID}/comments` 接口的请求参数.
+type CreateCommentRequest struct {
+	// ParentID 是被回复的评论，为空表示直接评论博客.
+	ParentID string `json:"parentID"`
+	Content  string `json:"content" valid:"required,stringlength(1|2048)"`
+}
+
+// CreateCommentResponse 指定了 `POST /v1/posts/{postID}/comments` 接口的返回参数.
+type CreateCommentResponse struct {
+	CommentID string `json:"commentID"`
+	// Status 是评论的审核状态，需要审核的博客下新发表的评论为 pending.
+	Status string `json:"status"`
+}
+
+// UpdateCommentRequest 指定了 `PUT /v1/posts/{postID}/comments/{commentID}` 接口的请求参数.
+type UpdateCommentRequest struct {
+	Content string `json:"content" valid:"required,stringlength(1|2048)"`
+}
+
+// CommentInfo 指定了评论的详细信息.
+type CommentInfo struct {
+	CommentID string `json:"commentID"`
+	PostID    string `json:"postID"`
+	ParentID  string `json:"parentID,omitempty"`
+	// Username 和 Content 在评论被删除后为空.
+	Username string `json:"username"`
+	Content  string `json:"content"`
+	Deleted  bool   `json:"deleted,omitempty"`
+	// Status 是评论的审核状态，可选值：pending, approved, rejected, spam.
+	Status string `json:"status"`
+	// Replies 是对该评论的回复，按创建时间正序排列.
+	Replies   []*CommentInfo `json:"replies,omitempty"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+}
+
+// ListCommentRequest 指定了 `GET /v1/posts/{postID}/comments` 接口的请求参数.
+type ListCommentRequest struct {
+	// Cursor 是上一次请求返回的 nextCursor，为空时返回第一页.
+	Cursor string `form:"cursor"`
+	// Limit 是每页返回的讨论串数量.
+	Limit int `form:"limit"`
+}
+
+// ListCommentResponse 指定了 `GET /v1/posts/{postID}/comments` 接口的返回参数.
+type ListCommentResponse struct {
+	// TotalCount 是直接评论博客的评论数，即讨论串的数量.
+	TotalCount int64 `json:"totalCount"`
+	// NextCursor 为空表示没有更多数据.
+	NextCursor string `json:"nextCursor"`
+	// Comments 是按创建时间倒序排列的讨论串，每个讨论串包含全部回复.
+	Comments []*CommentInfo `json:"comments"`
+}
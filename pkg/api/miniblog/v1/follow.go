@@ -0,0 +1,45 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// ListFollowRequest 指定了 `GET /v1/users/{name}/followers` 和 `GET /v1/users/{name}/following` 接口的请求参数.
+type ListFollowRequest struct {
+	// Cursor 是上一次请求返回的 nextCursor，为空时返回第一页.
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// FollowInfo 指定了关注关系中另一方用户的信息.
+type FollowInfo struct {
+	Username   string `json:"username"`
+	FollowedAt string `json:"followedAt"`
+	// Approved 表示被关注的用户是否批准了这次关注.
+	Approved bool `json:"approved"`
+}
+
+// ListFollowResponse 指定了 `GET /v1/users/{name}/followers` 和 `GET /v1/users/{name}/following` 接口的返回参数.
+type ListFollowResponse struct {
+	TotalCount int64 `json:"totalCount"`
+	// NextCursor 为空表示没有更多数据.
+	NextCursor string `json:"nextCursor"`
+	// Users 按关注时间倒序排列.
+	Users []*FollowInfo `json:"users"`
+}
+
+// ListTimelineRequest 指定了 `GET /v1/timeline` 接口的请求参数.
+type ListTimelineRequest struct {
+	// Cursor 是上一次请求返回的 nextCursor，为空时返回第一页.
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// ListTimelineResponse 指定了 `GET /v1/timeline` 接口的返回参数.
+// 时间线只支持游标分页，不返回总数.
+type ListTimelineResponse struct {
+	// NextCursor 为空表示没有更多数据.
+	NextCursor string      `json:"nextCursor"`
+	Posts      []*PostInfo `json:"posts"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// ListModerationQueueRequest 指定了 `GET /v1/moderation/queue` 接口的请求参数.
+type ListModerationQueueRequest struct {
+	// Cursor 是上一次请求返回的 nextCursor，为空时返回第一页.
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	// Status 只返回指定审核状态的评论，默认为 pending.
+	Status string `form:"status" valid:"in(pending|approved|rejected|spam)"`
+}
+
+// ListModerationQueueResponse 指定了 `GET /v1/moderation/queue` 接口的返回参数.
+type ListModerationQueueResponse struct {
+	TotalCount int64 `json:"totalCount"`
+	// NextCursor 为空表示没有更多数据.
+	NextCursor string `json:"nextCursor"`
+	// Comments 按创建时间倒序排列，不包含回复.
+	Comments []*CommentInfo `json:"comments"`
+}
+
+// ModerateCommentsRequest 指定了 `POST /v1/moderation/queue` 接口的请求参数.
+type ModerateCommentsRequest struct {
+	// CommentIDs 是要审核的评论，不属于当前用户博客的评论会被忽略，最多 100 条.
+	CommentIDs []string `json:"commentIDs"`
+	// Action 是审核操作，可选值：approve, reject, spam.
+	Action string `json:"action" valid:"required,in(approve|reject|spam)"`
+}
+
+// ModerateCommentsResponse 指定了 `POST /v1/moderation/queue` 接口的返回参数.
+type ModerateCommentsResponse struct {
+	// Count 是实际修改的评论数.
+	Count int64 `json:"count"`
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+import "time"
+
+// CreatePostRequest 指定了 `POST /v1/posts` 接口的请求参数.
+type CreatePostRequest struct {
+	Title   string `json:"title" valid:"required,stringlength(1|256)"`
+	Content string `json:"content" valid:"required,stringlength(1|10240)"`
+	// Status 是博客的发布状态，可选值：draft, scheduled, published，默认为 published.
+	Status string `json:"status" valid:"in(draft|scheduled|published)"`
+	// PublishAt 是 RFC3339 格式的定时发布时间，status 为 scheduled 时必须指定并且晚于当前时间.
+	PublishAt *time.Time `json:"publishAt"`
+	// Visibility 是博客的可见范围，可选值：public, unlisted, private, followers，默认为 public.
+	// unlisted 博客只能通过链接访问，followers 博客只有作者批准的关注者可见.
+	Visibility string `json:"visibility" valid:"in(public|unlisted|private|followers)"`
+	// Tags 是博客的标签，标签名不区分大小写，最多 10 个.
+	Tags []string `json:"tags"`
+	// ModerateComments 为 true 时，其他用户的评论需要博客所有者审核通过后才会公开.
+	ModerateComments bool `json:"moderateComments"`
+}
+
+// CreatePostResponse 指定了 `POST /v1/posts` 接口的返回参数.
+type CreatePostResponse struct {
+	PostID string `json:"postID"`
+}
+
+// GetPostResponse 指定了 `GET /v1/posts/{postID}` 接口的返回参数.
+type GetPostResponse PostInfo
+
+// UpdatePostRequest 指定了 `PUT /v1/posts/{postID}` 接口的请求参数.
+type UpdatePostRequest struct {
+	Title      *string    `json:"title" valid:"stringlength(1|256)"`
+	Content    *string    `json:"content" valid:"stringlength(1|10240)"`
+	Status     *string    `json:"status" valid:"in(draft|scheduled|published)"`
+	PublishAt  *time.Time `json:"publishAt"`
+	Visibility *string    `json:"visibility" valid:"in(public|unlisted|private|followers)"`
+	// Tags 不为空时替换博客的全部标签，传入空数组表示清除标签.
+	Tags             *[]string `json:"tags"`
+	ModerateComments *bool     `json:"moderateComments"`
+	// Revision 是本次修改所基于的修订号，指定时如果博客已经被其他人修改则返回冲突错误.
+	Revision *int `json:"revision"`
+}
+
+// UpdatePostResponse 指定了 `PUT /v1/posts/{postID}` 接口的返回参数.
+type UpdatePostResponse struct {
+	// Revision 是修改后博客的修订号.
+	Revision int `json:"revision"`
+}
+
+// PostInfo 指定了博客的详细信息.
+type PostInfo struct {
+	Username string `json:"username,omitempty"`
+	PostID   string `json:"postID,omitempty"`
+	Title    string `json:"title"`
+	// Content 是博客的 Markdown 源文本.
+	Content string `json:"content"`
+	// ContentHTML 是 Content 渲染并过滤后的 HTML，只在获取博客详情时返回.
+	ContentHTML string `json:"contentHTML,omitempty"`
+	// TOC 是根据 Content 中的标题生成的目录，只在获取博客详情时返回.
+	TOC  []*TOCItem `json:"toc,omitempty"`
+	Tags []*TagInfo `json:"tags"`
+	// Reactions 是各种回应的数量，没有人回应的类型不会返回.
+	Reactions map[string]int64 `json:"reactions"`
+	Revision  int              `json:"revision"`
+	Status    string           `json:"status"`
+	// Visibility 是博客的可见范围.
+	Visibility string `json:"visibility"`
+	// PublishAt 是定时发布的时间，只有 scheduled 状态的博客才会返回.
+	PublishAt        string `json:"publishAt,omitempty"`
+	PublishedAt      string `json:"publishedAt,omitempty"`
+	ModerateComments bool   `json:"moderateComments"`
+	// ViewCount 是博客的浏览次数，浏览次数定期批量写入数据库，会有短暂的延迟.
+	ViewCount int64  `json:"viewCount"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// TOCItem 是博客目录中的一项.
+type TOCItem struct {
+	Level int `json:"level"`
+	// ID 是标题在 contentHTML 中的锚点.
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// ListPostRequest 指定了 `GET /v1/posts` 和 `GET /v1/users/{name}/posts` 接口的请求参数.
+type ListPostRequest struct {
+	// Cursor 是上一次请求返回的 nextCursor，为空时返回第一页.
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+
+	// Author 只返回指定用户的博客，`GET /v1/users/{name}/posts` 接口会忽略该参数.
+	Author string `form:"author"`
+	// Since 和 Until 是 RFC3339 格式的时间，限定博客的创建时间范围 [since, until).
+	Since time.Time `form:"since"`
+	Until time.Time `form:"until"`
+	// TitlePrefix 只返回标题以指定前缀开头的博客.
+	TitlePrefix string `form:"titlePrefix"`
+	// Tag 只返回带有指定标签的博客，标签名不区分大小写.
+	Tag string `form:"tag"`
+	// Status 只返回指定状态的博客，草稿和定时发布的博客只有作者本人可以查询到.
+	Status string `form:"status" valid:"in(draft|scheduled|published)"`
+}
+
+// ListPostResponse 指定了 `GET /v1/posts` 和 `GET /v1/users/{name}/posts` 接口的返回参数.
+type ListPostResponse struct {
+	TotalCount int64 `json:"totalCount"`
+	// NextCursor 为空表示没有更多数据.
+	NextCursor string      `json:"nextCursor"`
+	Posts      []*PostInfo `json:"posts"`
+}
+
+// SearchPostRequest 指定了 `GET /v1/posts/search` 接口的请求参数.
+type SearchPostRequest struct {
+	Q      string `form:"q" valid:"required,stringlength(1|256)"`
+	Author string `form:"author"`
+	Offset int    `form:"offset" valid:"range(0|10000)"`
+	Limit  int    `form:"limit" valid:"range(0|100)"`
+}
+
+// SearchResult 是一条博客搜索结果.
+type SearchResult struct {
+	PostID   string  `json:"postID"`
+	Username string  `json:"username"`
+	Title    string  `json:"title"`
+	Score    float64 `json:"score"`
+	// Highlights 是 title、content 字段中命中关键词的片段，关键词使用 <mark> 标签包裹.
+	Highlights map[string][]string `json:"highlights"`
+	CreatedAt  string              `json:"createdAt"`
+}
+
+// SearchPostResponse 指定了 `GET /v1/posts/search` 接口的返回参数.
+type SearchPostResponse struct {
+	TotalCount int64           `json:"totalCount"`
+	Results    []*SearchResult `json:"results"`
+}
+
+// ListPostViewRequest 指定了 `GET /v1/posts/{postID}/views` 接口的请求参数.
+type ListPostViewRequest struct {
+	// Days 是返回最近多少天的浏览次数，包括今天，默认为 30.
+	Days int `form:"days" valid:"range(0|365)"`
+}
+
+// ListPostViewResponse 指定了 `GET /v1/posts/{postID}/views` 接口的返回参数.
+type ListPostViewResponse struct {
+	// ViewCount 是博客的总浏览次数.
+	ViewCount int64 `json:"viewCount"`
+	// Days 按日期顺序返回每天的浏览次数，没有浏览的日期不会返回.
+	Days []*DailyView `json:"days"`
+}
+
+// DailyView 指定了博客一天的浏览次数.
+type DailyView struct {
+	// Day 是 2006-01-02 格式的日期.
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// ListTrendingRequest 指定了 `GET /v1/posts/trending` 接口的请求参数.
+type ListTrendingRequest struct {
+	Limit int `form:"limit" valid:"range(0|100)"`
+}
+
+// ListTrendingResponse 指定了 `GET /v1/posts/trending` 接口的返回参数.
+type ListTrendingResponse struct {
+	// UpdatedAt 是排行的计算时间，排行定期计算，服务刚启动还没有计算时为空.
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	// Posts 按热门分数从高到低排列.
+	Posts []*TrendingPostInfo `json:"posts"`
+}
+
+// TrendingPostInfo 指定了热门博客的信息.
+type TrendingPostInfo struct {
+	*PostInfo
+	// Score 是博客的热门分数，由最近的浏览、回应和评论按时间衰减后加权求和得到.
+	Score float64 `json:"score"`
+}
+
+// ListRelatedRequest 指定了 `GET /v1/posts/:postID/related` 接口的请求参数.
+type ListRelatedRequest struct {
+	Limit int `form:"limit" valid:"range(0|20)"`
+}
+
+// ListRelatedResponse 指定了 `GET /v1/posts/:postID/related` 接口的返回参数.
+type ListRelatedResponse struct {
+	// Posts 按相似度从高到低排列.
+	Posts []*RelatedPostInfo `json:"posts"`
+}
+
+// RelatedPostInfo 指定了相关博客的信息.
+type RelatedPostInfo struct {
+	*PostInfo
+	// Score 是博客与指定博客的相似度，取值范围为 (0, 1].
+	Score float64 `json:"score"`
+}
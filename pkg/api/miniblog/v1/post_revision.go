@@ -0,0 +1,50 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// PostRevisionInfo 指定了博客修订记录的详细信息.
+type PostRevisionInfo struct {
+	PostID   string `json:"postID"`
+	Revision int    `json:"revision"`
+	// Username 是产生该修订的用户.
+	Username  string `json:"username"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ListPostRevisionRequest 指定了 `GET /v1/posts/{postID}/revisions` 接口的请求参数.
+type ListPostRevisionRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// ListPostRevisionResponse 指定了 `GET /v1/posts/{postID}/revisions` 接口的返回参数.
+type ListPostRevisionResponse struct {
+	TotalCount int64               `json:"totalCount"`
+	NextCursor string              `json:"nextCursor"`
+	Revisions  []*PostRevisionInfo `json:"revisions"`
+}
+
+// GetPostRevisionResponse 指定了 `GET /v1/posts/{postID}/revisions/{revision}` 接口的返回参数.
+type GetPostRevisionResponse PostRevisionInfo
+
+// DiffPostRevisionRequest 指定了 `GET /v1/posts/{postID}/revisions/diff` 接口的请求参数.
+type DiffPostRevisionRequest struct {
+	From int `form:"from" valid:"required"`
+	To   int `form:"to" valid:"required"`
+}
+
+// DiffPostRevisionResponse 指定了 `GET /v1/posts/{postID}/revisions/diff` 接口的返回参数.
+type DiffPostRevisionResponse struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Diff 是两个修订之间标题和正文的 unified diff，两个修订相同时为空.
+	Diff string `json:"diff"`
+}
+
+// RestorePostRevisionResponse 指定了 `POST /v1/posts/{postID}/revisions/{revision}/restore` 接口的返回参数.
+type RestorePostRevisionResponse UpdatePostResponse
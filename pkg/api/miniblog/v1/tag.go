@@ -0,0 +1,28 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// TagInfo 指定了标签的详细信息.
+type TagInfo struct {
+	// Slug 是标签规范化后的名字，可以用于 `GET /v1/posts?tag=` 过滤博客.
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// PostCount 是使用该标签的已发布公开博客数，只在获取标签列表时返回.
+	PostCount int64 `json:"postCount,omitempty"`
+}
+
+// ListTagRequest 指定了 `GET /v1/tags` 接口的请求参数.
+type ListTagRequest struct {
+	// Prefix 只返回 slug 以指定前缀开头的标签.
+	Prefix string `form:"prefix"`
+	Limit  int    `form:"limit" valid:"range(0|1000)"`
+}
+
+// ListTagResponse 指定了 `GET /v1/tags` 接口的返回参数.
+type ListTagResponse struct {
+	// Tags 按使用次数倒序排列.
+	Tags []*TagInfo `json:"tags"`
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// EnrollTOTPResponse 指定了 `POST /v1/users/{name}/totp` 接口的返回参数.
+type EnrollTOTPResponse struct {
+	// Secret 是 base32 编码的密钥，用户无法扫描二维码时可以手动输入.
+	Secret string `json:"secret"`
+	// URI 是身份验证器应用可以导入的 otpauth URI，通常以二维码的形式展示.
+	URI string `json:"uri"`
+}
+
+// VerifyTOTPRequest 指定了 `POST /v1/users/{name}/totp/verify` 接口的请求参数.
+type VerifyTOTPRequest struct {
+	// Code 是身份验证器生成的一次性密码.
+	Code string `json:"code" valid:"required,numeric,stringlength(6|6)"`
+}
+
+// VerifyTOTPResponse 指定了 `POST /v1/users/{name}/totp/verify` 接口的返回参数.
+type VerifyTOTPResponse struct {
+	// RecoveryCodes 是一次性恢复码，只在启用两步验证时返回一次，用户需要妥善保存.
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// DisableTOTPRequest 指定了 `DELETE /v1/users/{name}/totp` 接口的请求参数.
+type DisableTOTPRequest struct {
+	// Code 是身份验证器生成的一次性密码，也可以是一个没有使用过的恢复码.
+	Code string `json:"code" valid:"required,stringlength(6|32)"`
+}
+
+// RegenerateRecoveryCodesRequest 指定了 `POST /v1/users/{name}/totp/recovery-codes` 接口的请求参数.
+type RegenerateRecoveryCodesRequest struct {
+	// Code 是身份验证器生成的一次性密码.
+	Code string `json:"code" valid:"required,numeric,stringlength(6|6)"`
+}
+
+// RegenerateRecoveryCodesResponse 指定了 `POST /v1/users/{name}/totp/recovery-codes` 接口的返回参数，
+// 新的恢复码替换之前所有的恢复码.
+type RegenerateRecoveryCodesResponse VerifyTOTPResponse
@@ -0,0 +1,29 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package v1
+
+// CreateUploadResponse 指定了 `POST /v1/uploads` 接口的返回参数.
+type CreateUploadResponse struct {
+	// Hash 是文件内容的 SHA-256 摘要，相同内容的文件总是返回相同的 Hash.
+	Hash string `json:"hash"`
+	// URL 是文件的访问路径，可以直接在博客的 Markdown 中引用.
+	URL         string `json:"url"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// Width 和 Height 是图片的尺寸，只有图片才会返回.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Variants 是图片的缩略图，按宽度从小到大排列，只会生成比原图窄的尺寸.
+	Variants []*UploadVariant `json:"variants,omitempty"`
+}
+
+// UploadVariant 指定了图片缩略图的信息.
+type UploadVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
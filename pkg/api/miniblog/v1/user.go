@@ -23,6 +23,33 @@ type LoginRequest struct {
 // LoginResponse 指定了`POST /login`接口的返回参数
 type LoginResponse struct {
 	Token string `json:"token"`
+	// RefreshToken 用来在 Token 过期后换取新的 Token，每个刷新令牌只能使用一次.
+	RefreshToken string `json:"refreshToken"`
+	// TwoFactorRequired 为 true 表示用户启用了两步验证，此时不返回 Token，
+	// 需要使用 ChallengeToken 和一次性密码调用 `POST /login/2fa` 接口换取 Token.
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+}
+
+// LoginTwoFactorRequest 指定了 `POST /login/2fa` 接口的请求参数.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" valid:"required"`
+	// Code 是身份验证器生成的一次性密码，也可以是一个没有使用过的恢复码.
+	Code string `json:"code" valid:"required,stringlength(6|32)"`
+}
+
+// RefreshTokenRequest 指定了 `POST /v1/auth/refresh` 接口的请求参数.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" valid:"required"`
+}
+
+// RefreshTokenResponse 指定了 `POST /v1/auth/refresh` 接口的返回参数，返回的刷新令牌替换请求中的刷新令牌.
+type RefreshTokenResponse LoginResponse
+
+// LogoutRequest 指定了 `POST /logout` 接口的请求参数.
+type LogoutRequest struct {
+	// RefreshToken 是登录或者刷新时返回的刷新令牌，注销会撤销同一次登录产生的所有刷新令牌.
+	RefreshToken string `json:"refreshToken" valid:"required"`
 }
 
 // ChangePasswordRequest 指定了`POST 、v1/users/{name}/change-password`接口的请求参数
@@ -43,7 +70,7 @@ type UserInfo struct {
 	Nickname  string `json:"nickname"`
 	Email     string `json:"email"`
 	Phone     string `json:"phone"`
-	PostCount string `json:"postCount"`
+	PostCount int64  `json:"postCount"`
 	CreateAt  string `json:"createAt"`
 	UpdateAt  string `json:"updateAt"`
 }
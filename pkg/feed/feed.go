@@ -0,0 +1,178 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package feed 用来生成 Atom 1.0 (RFC 4287) 和 RSS 2.0 格式的订阅源.
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Feed 是与输出格式无关的订阅源.
+type Feed struct {
+	Title       string
+	Description string
+	// Link 是订阅源对应的网页地址.
+	Link string
+	// Self 是订阅源自身的地址，同时用作 Atom 订阅源的 ID.
+	Self string
+	// Updated 是订阅源最后一次更新的时间，通常为最近更新的条目的更新时间.
+	Updated time.Time
+	Entries []*Entry
+}
+
+// Entry 是订阅源中的一个条目.
+type Entry struct {
+	Title string
+	// Link 是条目的网页地址，同时用作条目的 ID.
+	Link      string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+	// Content 是条目的 HTML 内容.
+	Content string
+}
+
+// Atom 返回 Atom 1.0 格式的订阅源.
+func (f *Feed) Atom() ([]byte, error) {
+	feed := &atomFeed{
+		XMLNS:   "http://www.w3.org/2005/Atom",
+		ID:      f.Self,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.Self},
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+		},
+	}
+	if f.Description != "" {
+		feed.Subtitle = f.Description
+	}
+
+	for _, e := range f.Entries {
+		feed.Entries = append(feed.Entries, &atomEntry{
+			ID:        e.Link,
+			Title:     e.Title,
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: e.Link},
+			Author:    atomPerson{Name: e.Author},
+			Published: e.Published.UTC().Format(time.RFC3339),
+			Updated:   e.Updated.UTC().Format(time.RFC3339),
+			Content:   atomText{Type: "html", Body: e.Content},
+		})
+	}
+
+	return marshal(feed)
+}
+
+// RSS 返回 RSS 2.0 格式的订阅源.
+func (f *Feed) RSS() ([]byte, error) {
+	channel := &rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		AtomLink:      atomLink{Rel: "self", Type: "application/rss+xml", Href: f.Self},
+	}
+	// description 是 RSS 2.0 的必填字段
+	if channel.Description == "" {
+		channel.Description = f.Title
+	}
+
+	for _, e := range f.Entries {
+		channel.Items = append(channel.Items, &rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{IsPermaLink: "true", Value: e.Link},
+			Creator:     e.Author,
+			PubDate:     e.Published.UTC().Format(time.RFC1123Z),
+			Description: e.Content,
+		})
+	}
+
+	return marshal(&rssFeed{
+		Version: "2.0",
+		XMLNSA:  "http://www.w3.org/2005/Atom",
+		XMLNSDC: "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	})
+}
+
+// marshal 将 v 编码为带 XML 声明的文档.
+func marshal(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+type atomFeed struct {
+	XMLName  xml.Name     `xml:"feed"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle,omitempty"`
+	Updated  string       `xml:"updated"`
+	Links    []atomLink   `xml:"link"`
+	Entries  []*atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Link      atomLink   `xml:"link"`
+	Author    atomPerson `xml:"author"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Content   atomText   `xml:"content"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name    `xml:"rss"`
+	Version string      `xml:"version,attr"`
+	XMLNSA  string      `xml:"xmlns:atom,attr"`
+	XMLNSDC string      `xml:"xmlns:dc,attr"`
+	Channel *rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	AtomLink      atomLink   `xml:"atom:link"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Creator     string  `xml:"dc:creator"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
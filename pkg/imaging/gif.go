@@ -0,0 +1,82 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package imaging
+
+import (
+	"encoding/binary"
+)
+
+// GIF 文件中各个块的起始字节.
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2C
+	gifTrailer         = 0x3B
+)
+
+// gifFrames 在不解码图像数据的情况下遍历 GIF 文件的块结构，返回其中的帧数和所有帧的像素总数.
+// 文件结构不完整时 ok 为 false.
+func gifFrames(data []byte) (frames, pixels int, ok bool) {
+	// 6 字节的文件头和 7 字节的逻辑屏幕描述符
+	if len(data) < 13 {
+		return 0, 0, false
+	}
+
+	i := 13 + colorTableSize(data[10])
+	for i < len(data) {
+		switch data[i] {
+		case gifExtension:
+			// 扩展标签之后是数据子块
+			if i+2 > len(data) {
+				return 0, 0, false
+			}
+			if i = skipSubBlocks(data, i+2); i < 0 {
+				return 0, 0, false
+			}
+		case gifImageDescriptor:
+			// 图像描述符共 10 字节，之后是可选的局部颜色表、1 字节的 LZW 最小码长和数据子块
+			if i+10 > len(data) {
+				return 0, 0, false
+			}
+			w := int(binary.LittleEndian.Uint16(data[i+5:]))
+			h := int(binary.LittleEndian.Uint16(data[i+7:]))
+			frames++
+			pixels += w * h
+
+			if i = skipSubBlocks(data, i+10+colorTableSize(data[i+9])+1); i < 0 {
+				return 0, 0, false
+			}
+		case gifTrailer:
+			return frames, pixels, true
+		default:
+			return 0, 0, false
+		}
+	}
+
+	return 0, 0, false
+}
+
+// colorTableSize 返回打包字段 packed 描述的颜色表的字节数，没有颜色表时返回 0.
+func colorTableSize(packed byte) int {
+	if packed&0x80 == 0 {
+		return 0
+	}
+
+	return 3 << (packed&0x07 + 1)
+}
+
+// skipSubBlocks 跳过从 i 开始的数据子块，返回终止块之后的位置，数据不完整时返回 -1.
+func skipSubBlocks(data []byte, i int) int {
+	for i < len(data) {
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i
+		}
+		i += size
+	}
+
+	return -1
+}
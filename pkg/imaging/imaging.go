@@ -0,0 +1,152 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package imaging 提供上传图片的元数据清理、缩放和重新编码，只依赖纯 Go 实现.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+// maxPixels 是允许处理的图片最大像素数，防止解码超大尺寸的图片耗尽内存.
+// 常见的 2400 万像素相机照片（6000x4000）解码后约占用 96MB 内存.
+const maxPixels = 24_000_000
+
+// maxGIFPixels 是 GIF 所有帧的像素总数上限，GIF 的每一帧解码后每个像素占用 1 字节，
+// 与 maxPixels 的 RGBA 图片占用的内存相当.
+const maxGIFPixels = 4 * maxPixels
+
+// maxGIFFrames 是 GIF 的最大帧数，限制大量小尺寸帧的额外开销.
+const maxGIFFrames = 1000
+
+// maxDecodes 是同时解码的图片数，与 maxPixels 一起限制图片处理占用的内存.
+const maxDecodes = 4
+
+// decodes 用作信号量，限制同时解码的图片数.
+var decodes = make(chan struct{}, maxDecodes)
+
+// jpegQuality 是重新编码 JPEG 时使用的质量.
+const jpegQuality = 90
+
+// ErrInvalidImage 表示图片无法解码或者尺寸超出限制.
+var ErrInvalidImage = errors.New("imaging: invalid image")
+
+// Image 是去除元数据后的图片.
+type Image struct {
+	// Data 是去除元数据后的图片文件内容，格式与原始图片相同.
+	Data []byte
+	// Format 是图片格式：jpeg、png、gif 或 webp.
+	Format string
+	// Image 是解码后的图片，已经根据 EXIF 中的方向信息旋转，GIF 只包含第一帧.
+	Image image.Image
+}
+
+// Strip 去除图片中的 EXIF、XMP 等元数据.
+// JPEG、PNG 和 GIF 通过重新编码去除所有元数据，JPEG 在重新编码前会根据 EXIF 中的方向信息旋转，
+// 因为 Go 没有 WebP 编码器，WebP 只删除其中的 EXIF 和 XMP 数据块.
+// 同时最多有 maxDecodes 个 Strip 调用在解码图片，其余的调用会等待.
+func Strip(data []byte) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrInvalidImage
+	}
+
+	decodes <- struct{}{}
+	defer func() { <-decodes }()
+
+	if format == "gif" {
+		return stripGIF(data)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		img = orient(img, orientation(data))
+		err = Encode(&buf, img, format)
+	case "png":
+		err = Encode(&buf, img, format)
+	case "webp":
+		_, err = buf.Write(stripWebP(data))
+	default:
+		return nil, ErrInvalidImage
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Image{Data: buf.Bytes(), Format: format, Image: img}, nil
+}
+
+// stripGIF 重新编码 GIF 的全部帧，去除注释和应用扩展中的元数据，保留动画.
+// 解码之前检查帧数和所有帧的像素总数，每一帧都不超过 maxPixels 的 GIF 也可能包含大量的帧.
+func stripGIF(data []byte) (*Image, error) {
+	frames, pixels, ok := gifFrames(data)
+	if !ok || frames > maxGIFFrames || pixels > maxGIFPixels {
+		return nil, ErrInvalidImage
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(g.Image) == 0 {
+		return nil, ErrInvalidImage
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		return nil, err
+	}
+
+	return &Image{Data: buf.Bytes(), Format: "gif", Image: g.Image[0]}, nil
+}
+
+// Resize 将图片等比缩放到 width 像素宽.
+func Resize(img image.Image, width int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, ScaledHeight(img, width)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return dst
+}
+
+// ScaledHeight 返回图片等比缩放到 width 像素宽之后的高度.
+func ScaledHeight(img image.Image, width int) int {
+	b := img.Bounds()
+
+	return max(1, int(math.Round(float64(b.Dy())*float64(width)/float64(b.Dx()))))
+}
+
+// Encode 按照 format 编码图片，format 为 jpeg 或 png.
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		return png.Encode(w, img)
+	default:
+		return ErrInvalidImage
+	}
+}
+
+// Opaque 返回图片是否不包含透明像素.
+func Opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	return false
+}
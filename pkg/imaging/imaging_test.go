@@ -0,0 +1,331 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// 测试图片四个象限的颜色.
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+func TestStripJPEG(t *testing.T) {
+	// 原图 32x16，左上红、右上绿、左下蓝、右下白，want 是按照方向信息旋转后四个象限的颜色
+	tests := []struct {
+		orientation int
+		width       int
+		height      int
+		want        [4]color.RGBA // 左上、右上、左下、右下
+	}{
+		{orientation: 1, width: 32, height: 16, want: [4]color.RGBA{red, green, blue, white}},
+		{orientation: 2, width: 32, height: 16, want: [4]color.RGBA{green, red, white, blue}},
+		{orientation: 3, width: 32, height: 16, want: [4]color.RGBA{white, blue, green, red}},
+		{orientation: 4, width: 32, height: 16, want: [4]color.RGBA{blue, white, red, green}},
+		{orientation: 5, width: 16, height: 32, want: [4]color.RGBA{red, blue, green, white}},
+		{orientation: 6, width: 16, height: 32, want: [4]color.RGBA{blue, red, white, green}},
+		{orientation: 7, width: 16, height: 32, want: [4]color.RGBA{white, green, blue, red}},
+		{orientation: 8, width: 16, height: 32, want: [4]color.RGBA{green, white, red, blue}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.orientation), func(t *testing.T) {
+			data := exifJPEG(t, tt.orientation)
+			if got := orientation(data); got != tt.orientation {
+				t.Fatalf("orientation() = %d, want %d", got, tt.orientation)
+			}
+
+			img, err := Strip(data)
+			if err != nil {
+				t.Fatalf("Strip() returned error: %v", err)
+			}
+			if bytes.Contains(img.Data, []byte("Exif\x00\x00")) || bytes.Contains(img.Data, []byte{0xFF, 0xE1}) {
+				t.Errorf("Strip() output still contains EXIF data")
+			}
+			if got := orientation(img.Data); got != 1 {
+				t.Errorf("orientation() of Strip() output = %d, want 1", got)
+			}
+
+			decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+			if err != nil {
+				t.Fatalf("jpeg.Decode() returned error: %v", err)
+			}
+			b := decoded.Bounds()
+			if b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Fatalf("Strip() output size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+
+			points := [4]image.Point{
+				{b.Dx() / 4, b.Dy() / 4},
+				{b.Dx() * 3 / 4, b.Dy() / 4},
+				{b.Dx() / 4, b.Dy() * 3 / 4},
+				{b.Dx() * 3 / 4, b.Dy() * 3 / 4},
+			}
+			for i, p := range points {
+				if got := nearest(decoded.At(p.X, p.Y)); got != tt.want[i] {
+					t.Errorf("Strip() output at %v = %v, want %v", p, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	data := metadataWebP(t)
+
+	img, err := Strip(data)
+	if err != nil {
+		t.Fatalf("Strip() returned error: %v", err)
+	}
+
+	for _, s := range []string{"EXIF", "XMP ", "Exif\x00\x00", "xmpmeta"} {
+		if bytes.Contains(img.Data, []byte(s)) {
+			t.Errorf("Strip() output still contains %q", s)
+		}
+	}
+	if flags := img.Data[20]; flags&(webpFlagEXIF|webpFlagXMP) != 0 {
+		t.Errorf("Strip() output VP8X flags = %#x, want EXIF and XMP flags cleared", flags)
+	}
+	if size := binary.LittleEndian.Uint32(img.Data[4:]); int(size) != len(img.Data)-8 {
+		t.Errorf("Strip() output RIFF size = %d, want %d", size, len(img.Data)-8)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("image.Decode() of Strip() output returned error: %v", err)
+	}
+	if decoded.Bounds() != img.Image.Bounds() {
+		t.Errorf("Strip() output bounds = %v, want %v", decoded.Bounds(), img.Image.Bounds())
+	}
+}
+
+func TestStripGIF(t *testing.T) {
+	animation := encodeGIF(t, 2, 8, 8)
+	// 在逻辑屏幕描述符和全局颜色表之后插入一个注释扩展
+	header := 13 + colorTableSize(animation[10])
+	comment := append([]byte{gifExtension, 0xFE, 6}, "secret\x00"...)
+	withComment := append(append(append([]byte{}, animation[:header]...), comment...), animation[header:]...)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFrames int
+		wantErr    error
+	}{
+		{name: "animation", data: withComment, wantFrames: 2},
+		{name: "too many frames", data: encodeGIF(t, maxGIFFrames+1, 1, 1), wantErr: ErrInvalidImage},
+		// 每一帧都是 4000x4000，单独检查每一帧的尺寸不会拒绝
+		{name: "too many pixels", data: bombGIF(4000, 4000, maxGIFPixels/(4000*4000)+1), wantErr: ErrInvalidImage},
+		{name: "missing trailer", data: animation[:len(animation)-1], wantErr: ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Strip(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Strip() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Strip() returned error: %v", err)
+			}
+
+			if bytes.Contains(img.Data, []byte("secret")) {
+				t.Errorf("Strip() output still contains the comment")
+			}
+			g, err := gif.DecodeAll(bytes.NewReader(img.Data))
+			if err != nil {
+				t.Fatalf("gif.DecodeAll() of Strip() output returned error: %v", err)
+			}
+			if len(g.Image) != tt.wantFrames {
+				t.Errorf("Strip() output frames = %d, want %d", len(g.Image), tt.wantFrames)
+			}
+		})
+	}
+}
+
+// TestGIFFrames 检查不解码图像数据时统计的帧数和像素总数，解码器会在分配每一帧的内存之后才发现图像数据不完整.
+func TestGIFFrames(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFrames int
+		wantPixels int
+		wantOK     bool
+	}{
+		{name: "animation", data: encodeGIF(t, 3, 8, 4), wantFrames: 3, wantPixels: 3 * 8 * 4, wantOK: true},
+		{name: "bomb", data: bombGIF(4000, 4000, 7), wantFrames: 7, wantPixels: 7 * 4000 * 4000, wantOK: true},
+		{name: "truncated", data: bombGIF(4000, 4000, 7)[:40]},
+		{name: "unknown block", data: append(bombGIF(1, 1, 1)[:19], 0x00)},
+		{name: "too short", data: []byte("GIF89a")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, pixels, ok := gifFrames(tt.data)
+			if ok != tt.wantOK || (ok && (frames != tt.wantFrames || pixels != tt.wantPixels)) {
+				t.Errorf("gifFrames() = %d, %d, %v, want %d, %d, %v", frames, pixels, ok, tt.wantFrames, tt.wantPixels, tt.wantOK)
+			}
+		})
+	}
+}
+
+// exifJPEG 返回一个 32x16 的 JPEG 图片，APP1 中包含方向为 o 的 EXIF 信息和 GPS 信息.
+func exifJPEG(t *testing.T, o int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			c := [4]color.RGBA{red, green, blue, white}[y/8*2+x/16]
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("jpeg.Encode() returned error: %v", err)
+	}
+
+	segment := append([]byte("Exif\x00\x00"), exifTIFF(o)...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// exifTIFF 返回小端序的 EXIF TIFF 结构，IFD0 包含方向 o 和 GPS IFD 的偏移，GPS IFD 包含纬度.
+func exifTIFF(o int) []byte {
+	le := binary.LittleEndian
+	tiff := []byte("II\x2A\x00")
+	tiff = le.AppendUint32(tiff, 8)
+	tiff = le.AppendUint16(tiff, 2)
+	tiff = append(le.AppendUint16(le.AppendUint16(tiff, orientationTag), 3), 1, 0, 0, 0)
+	tiff = append(le.AppendUint16(tiff, uint16(o)), 0, 0)
+	tiff = append(le.AppendUint16(le.AppendUint16(tiff, 0x8825), 4), 1, 0, 0, 0)
+	tiff = le.AppendUint32(tiff, 38)
+	tiff = le.AppendUint32(tiff, 0)
+	tiff = le.AppendUint16(tiff, 2)
+	tiff = append(le.AppendUint16(le.AppendUint16(tiff, 0x0001), 2), 2, 0, 0, 0)
+	tiff = append(tiff, 'N', 0, 0, 0)
+	tiff = append(le.AppendUint16(le.AppendUint16(tiff, 0x0002), 5), 3, 0, 0, 0)
+	tiff = le.AppendUint32(tiff, 68)
+	tiff = le.AppendUint32(tiff, 0)
+	for _, v := range []uint32{31, 1, 14, 1, 1500, 100} {
+		tiff = le.AppendUint32(tiff, v)
+	}
+
+	return tiff
+}
+
+// metadataWebP 将 testdata 中的无损 WebP 图片放入 VP8X 扩展格式，并添加 EXIF 和 XMP 数据块.
+func metadataWebP(t *testing.T) []byte {
+	t.Helper()
+
+	plain, err := os.ReadFile("testdata/lossless.webp")
+	if err != nil {
+		t.Fatalf("ReadFile() returned error: %v", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(plain))
+	if err != nil {
+		t.Fatalf("DecodeConfig() returned error: %v", err)
+	}
+
+	le := binary.LittleEndian
+	chunk := func(out []byte, fourcc string, payload []byte) []byte {
+		out = le.AppendUint32(append(out, fourcc...), uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	vp8x := []byte{webpFlagEXIF | webpFlagXMP, 0, 0, 0}
+	vp8x = append(vp8x, le.AppendUint32(nil, uint32(cfg.Width-1))[:3]...)
+	vp8x = append(vp8x, le.AppendUint32(nil, uint32(cfg.Height-1))[:3]...)
+
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	data = chunk(data, "VP8X", vp8x)
+	// 原图只包含一个 VP8L 数据块
+	data = append(data, plain[12:]...)
+	data = chunk(data, "EXIF", append([]byte("Exif\x00\x00"), exifTIFF(6)...))
+	data = chunk(data, "XMP ", []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><exif:GPSLatitude>31,14N</exif:GPSLatitude></x:xmpmeta>`))
+	le.PutUint32(data[4:], uint32(len(data)-8))
+
+	return data
+}
+
+// encodeGIF 返回一个包含 frames 帧、每帧 width x height 的 GIF 动画.
+func encodeGIF(t *testing.T, frames, width, height int) []byte {
+	t.Helper()
+
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black, color.White})
+		frame.SetColorIndex(0, 0, uint8(i%2))
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatalf("gif.EncodeAll() returned error: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// bombGIF 返回一个包含 frames 帧、每帧 width x height 的 GIF，每一帧只有很少的图像数据，解码时仍然会按照帧的尺寸分配内存.
+func bombGIF(width, height, frames int) []byte {
+	le := binary.LittleEndian
+
+	data := append([]byte("GIF89a"), le.AppendUint16(le.AppendUint16(nil, uint16(width)), uint16(height))...)
+	// 2 色的全局颜色表
+	data = append(data, 0x80, 0, 0, 0, 0, 0, 255, 255, 255)
+	for i := 0; i < frames; i++ {
+		data = append(data, gifImageDescriptor, 0, 0, 0, 0)
+		data = le.AppendUint16(le.AppendUint16(data, uint16(width)), uint16(height))
+		data = append(data, 0, 2, 2, 0x4C, 0x01, 0)
+	}
+
+	return append(data, gifTrailer)
+}
+
+// nearest 返回四个象限颜色中与 c 最接近的颜色，用来比较有损压缩后的颜色.
+func nearest(c color.Color) color.RGBA {
+	r, g, b, _ := c.RGBA()
+
+	var best color.RGBA
+	bestDistance := -1
+	for _, candidate := range []color.RGBA{red, green, blue, white} {
+		dr := int(r>>8) - int(candidate.R)
+		dg := int(g>>8) - int(candidate.G)
+		db := int(b>>8) - int(candidate.B)
+		if d := dr*dr + dg*dg + db*db; bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// VP8X 数据块中表示包含 EXIF 和 XMP 元数据的标志位.
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// stripWebP 删除 WebP 文件中的 EXIF 和 XMP 数据块，并清除 VP8X 中对应的标志位.
+// data 必须是已经成功解码的 WebP 文件.
+func stripWebP(data []byte) []byte {
+	if len(data) < 12 || !bytes.Equal(data[:4], []byte("RIFF")) || !bytes.Equal(data[8:12], []byte("WEBP")) {
+		return data
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+
+	for i := 12; i+8 <= len(data); {
+		fourcc := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		// 数据块的长度为奇数时后面有一个填充字节
+		end := i + 8 + size + size&1
+		if end > len(data) {
+			end = len(data)
+		}
+
+		switch fourcc {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				out[start+8] &^= webpFlagEXIF | webpFlagXMP
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+
+	return out
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// highlightStyle 是代码高亮使用的 chroma 样式.
+const highlightStyle = "github"
+
+// Heading 是目录中的一项，对应文档中的一个标题.
+type Heading struct {
+	Level int
+	// ID 是标题在 HTML 中的锚点，可以通过 `#<ID>` 跳转.
+	ID    string
+	Title string
+}
+
+// Document 是 Markdown 渲染的结果.
+type Document struct {
+	// HTML 是经过安全过滤的 HTML，可以直接嵌入页面.
+	HTML string
+	// TOC 是按照文档顺序排列的标题列表.
+	TOC []Heading
+}
+
+var (
+	md = goldmark.New(
+		// CommonMark 加上 GFM 扩展（表格、删除线、自动链接、任务列表）
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(highlightStyle),
+				// 使用 CSS class 而不是内联样式，方便过滤 HTML 和切换主题
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	policy = newPolicy()
+
+	cache *lru.Cache[[sha256.Size]byte, *Document]
+	once  sync.Once
+)
+
+// newPolicy 创建 HTML 过滤策略：在 UGC 策略的基础上允许代码高亮的 class 和任务列表的复选框.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("pre", "code", "span", "div")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
+
+// sanitize 使用 policy 过滤 HTML，并删除不是复选框的 input 元素.
+// bluemonday 只能逐个过滤属性，去掉 type 之后的 <input disabled> 会被浏览器显示为文本框.
+func sanitize(s string) string {
+	clean := policy.Sanitize(s)
+	if !strings.Contains(clean, "<input") {
+		return clean
+	}
+
+	var buf strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(clean))
+	for z.Next() != xhtml.ErrorToken {
+		raw := string(z.Raw())
+		if tok := z.Token(); tok.DataAtom == atom.Input && !isCheckbox(tok) {
+			continue
+		}
+		buf.WriteString(raw)
+	}
+
+	return buf.String()
+}
+
+// isCheckbox 返回 input 元素是否是复选框.
+func isCheckbox(tok xhtml.Token) bool {
+	for _, attr := range tok.Attr {
+		if attr.Key == "type" {
+			return attr.Val == "checkbox"
+		}
+	}
+
+	return false
+}
+
+// Init 设置渲染结果缓存可以保存的文档数，需要在调用 Render 之前调用.
+func Init(cacheSize int) {
+	once.Do(func() {
+		if cacheSize <= 0 {
+			cacheSize = 1024
+		}
+		cache, _ = lru.New[[sha256.Size]byte, *Document](cacheSize)
+	})
+}
+
+// Render 将 Markdown 源文本渲染成安全的 HTML 并生成目录.
+// 渲染结果按照源文本的摘要缓存，同一个版本的文档只会渲染一次.
+func Render(source string) (*Document, error) {
+	Init(0)
+
+	key := sha256.Sum256([]byte(source))
+	if doc, ok := cache.Get(key); ok {
+		return doc, nil
+	}
+
+	src := []byte(source)
+	root := md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, root); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		HTML: sanitize(buf.String()),
+		TOC:  toc(root, src),
+	}
+	cache.Add(key, doc)
+
+	return doc, nil
+}
+
+// toc 遍历语法树，收集所有标题生成目录.
+func toc(root ast.Node, src []byte) []Heading {
+	var headings []Heading
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		headings = append(headings, Heading{
+			Level: heading.Level,
+			ID:    string(idBytes),
+			Title: string(heading.Text(src)),
+		})
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return headings
+}
+
+// Summary 返回 Markdown 源文本渲染后的纯文本摘要，最多保留 n 个字符，用于页面描述等场景.
+func Summary(source string, n int) (string, error) {
+	doc, err := Render(source)
+	if err != nil {
+		return "", err
+	}
+
+	// 去掉所有标签后合并空白字符
+	plain := html.UnescapeString(bluemonday.StrictPolicy().Sanitize(doc.HTML))
+	plain = strings.Join(strings.Fields(plain), " ")
+	if runes := []rune(plain); len(runes) > n {
+		plain = string(runes[:n]) + "…"
+	}
+
+	return plain, nil
+}
+
+// StyleSheet 返回代码高亮需要的 CSS.
+func StyleSheet() (string, error) {
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&buf, styles.Get(highlightStyle)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
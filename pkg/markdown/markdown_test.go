@@ -0,0 +1,104 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSanitize 直接使用 HTML 测试过滤策略，不依赖 goldmark 是否会省略原始 HTML.
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "script", in: `<p>x</p><script>alert(1)</script>`, want: `<p>x</p>`},
+		{name: "javascript link", in: `<a href="javascript:alert(1)">x</a>`, want: `x`},
+		{name: "mixed case javascript link", in: `<a href="JaVaScRiPt:alert(1)">x</a>`, want: `x`},
+		{name: "data link", in: `<a href="data:text/html,<script>alert(1)</script>">x</a>`, want: `x`},
+		{name: "event handler", in: `<p onclick="alert(1)" onmouseover="alert(1)">x</p>`, want: `<p>x</p>`},
+		{name: "image event handler", in: `<img src="/a.png" onerror="alert(1)">`, want: `<img src="/a.png">`},
+		{name: "style", in: `<span class="k" style="background:url(javascript:alert(1))">x</span>`, want: `<span class="k">x</span>`},
+		{name: "iframe", in: `<iframe src="https://example.com"></iframe>`, want: ``},
+		{name: "invalid class", in: `<code class="a&quot; onclick=&quot;alert(1)">x</code>`, want: `<code>x</code>`},
+		{name: "class on other elements", in: `<p class="k">x</p>`, want: `<p>x</p>`},
+		{name: "text input", in: `<input type="text" value="x">`, want: ``},
+		{name: "input without type", in: `<input disabled name="q">`, want: ``},
+		{name: "password input", in: `<input type="password" checked>`, want: ``},
+		{name: "hidden input in form", in: `<form action="/v1/posts"><input type="hidden" name="title" value="x"></form>`, want: ``},
+		{name: "checkbox", in: `<input checked disabled type="checkbox" onclick="alert(1)">`, want: `<input checked="" disabled="" type="checkbox">`},
+		{name: "safe link", in: `<a href="https://example.com" title="t">x</a>`, want: `<a href="https://example.com" title="t" rel="nofollow">x</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize(tt.in); got != tt.want {
+				t.Errorf("sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderStripsUnsafeHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		unsafe []string
+	}{
+		{name: "script", source: "<script>alert(1)</script>", unsafe: []string{"<script", "alert"}},
+		{name: "inline event handler", source: "x <img src=x onerror=alert(1)> y", unsafe: []string{"onerror", "alert"}},
+		{name: "javascript link", source: "[x](javascript:alert(1))", unsafe: []string{"javascript:"}},
+		{name: "javascript autolink", source: "<javascript:alert(1)>", unsafe: []string{"href"}},
+		{name: "javascript image", source: "![x](javascript:alert(1))", unsafe: []string{"javascript:", "src"}},
+		{name: "text input", source: `<input type="text" value="x">`, unsafe: []string{"<input"}},
+		{name: "html block", source: "<div onclick=\"alert(1)\">\n\nx\n\n</div>", unsafe: []string{"onclick", "<div"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Render(tt.source)
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			for _, s := range tt.unsafe {
+				if strings.Contains(doc.HTML, s) {
+					t.Errorf("Render(%q) = %q, should not contain %q", tt.source, doc.HTML, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc, err := Render("# Title\n\n## Tasks\n\n- [x] done\n- [ ] todo\n\n```go\nfunc main() {}\n```\n")
+	if err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		`<h1 id="title">Title</h1>`,
+		`<input checked="" disabled="" type="checkbox"> done`,
+		`<input disabled="" type="checkbox"> todo`,
+		`<pre class="chroma"><code>`,
+		`<span class="kd">func</span>`,
+	} {
+		if !strings.Contains(doc.HTML, want) {
+			t.Errorf("Render() = %q, want it to contain %q", doc.HTML, want)
+		}
+	}
+
+	wantTOC := []Heading{{Level: 1, ID: "title", Title: "Title"}, {Level: 2, ID: "tasks", Title: "Tasks"}}
+	if len(doc.TOC) != len(wantTOC) {
+		t.Fatalf("Render() TOC = %+v, want %+v", doc.TOC, wantTOC)
+	}
+	for i := range wantTOC {
+		if doc.TOC[i] != wantTOC[i] {
+			t.Errorf("Render() TOC[%d] = %+v, want %+v", i, doc.TOC[i], wantTOC[i])
+		}
+	}
+}
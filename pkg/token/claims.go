@@ -0,0 +1,83 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package token
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Claims 是miniblog签发的token中的claims，用户名保存在sub中
+type Claims struct {
+	jwt.RegisteredClaims
+	// Roles 是用户的角色
+	Roles []string `json:"roles,omitempty"`
+	// Scopes 是token可以访问的权限范围，按照RFC 8693编码为以空格分隔的scope
+	Scopes Scopes `json:"scope,omitempty"`
+}
+
+// NewClaims 创建subject的claims，其它registered claims在签发时生成
+func NewClaims(subject string, roles []string, scopes []string) *Claims {
+	return &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: subject}, Roles: roles, Scopes: scopes}
+}
+
+// Valid 校验claims的有效期、签发者和受众，校验时间时允许配置的时钟偏差
+func (c *Claims) Valid() error {
+	now := time.Now()
+	if !c.VerifyExpiresAt(now.Add(-cfg.Leeway), true) {
+		return jwt.ErrTokenExpired
+	}
+	if !c.VerifyNotBefore(now.Add(cfg.Leeway), false) {
+		return jwt.ErrTokenNotValidYet
+	}
+	if !c.VerifyIssuedAt(now.Add(cfg.Leeway), false) {
+		return jwt.ErrTokenUsedBeforeIssued
+	}
+	if cfg.Issuer != "" && !c.VerifyIssuer(cfg.Issuer, true) {
+		return jwt.ErrTokenInvalidIssuer
+	}
+	if len(cfg.Audience) > 0 && !slices.ContainsFunc(cfg.Audience, func(aud string) bool { return c.VerifyAudience(aud, true) }) {
+		return jwt.ErrTokenInvalidAudience
+	}
+	if c.Subject == "" {
+		return jwt.ErrTokenMalformed
+	}
+
+	return nil
+}
+
+// HasScope 返回token是否可以访问scope
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// HasRole 返回用户是否具有role角色
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// Scopes 是以空格分隔的scope列表
+type Scopes []string
+
+// MarshalJSON 将scope列表编码为以空格分隔的字符串
+func (s Scopes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(s, " "))
+}
+
+// UnmarshalJSON 解析以空格分隔的scope字符串
+func (s *Scopes) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*s = strings.Fields(str)
+
+	return nil
+}
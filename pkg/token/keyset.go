@@ -0,0 +1,387 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// 支持的非对称签名算法.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// rsaKeyBits 是生成的 RSA 密钥长度.
+const rsaKeyBits = 2048
+
+// keyExt 是密钥文件的扩展名，文件名（不含扩展名）就是密钥的 kid.
+const keyExt = ".pem"
+
+// kidTimeFormat 是 kid 开头的生成时间的格式.
+const kidTimeFormat = "20060102T150405Z"
+
+// missReloadInterval 是遇到未知的 kid 时重新加载密钥目录的最小间隔，防止携带随机 kid 的请求频繁读取密钥目录.
+const missReloadInterval = 10 * time.Second
+
+// Key 是一个签名密钥.
+type Key struct {
+	// ID 是密钥的 kid，写入 Token 头部，验证时用来查找对应的公钥.
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	// CreatedAt 是 kid 中记录的生成时间，不符合命名规则的密钥使用文件的修改时间.
+	CreatedAt time.Time
+}
+
+// method 返回密钥对应的 JWT 签名方法.
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+// KeySet 是从密钥目录加载的一组签名密钥，可以被多个 goroutine 同时访问.
+// 目录中的所有密钥都可以用来验证 Token，生成超过激活延迟的最新密钥用来签发 Token. 多个 miniblog 实例可以共享同一个密钥目录.
+type KeySet struct {
+	dir  string
+	mu   sync.RWMutex
+	keys []*Key // 按创建时间从旧到新排列
+	// activation 是新密钥生成之后开始用来签发 Token 的延迟，在此之前新密钥只通过 JWKS 公开.
+	activation time.Duration
+	// missMu 保护 lastMiss，lastMiss 是最近一次因为未知的 kid 重新加载密钥目录的时间.
+	missMu   sync.Mutex
+	lastMiss time.Time
+}
+
+// LoadKeySet 加载 dir 目录中的所有密钥，目录不存在时创建.
+func LoadKeySet(dir string) (*KeySet, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{dir: dir}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload 重新加载密钥目录，使其它实例轮换的密钥生效.
+func (s *KeySet) Reload() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyExt {
+			continue
+		}
+
+		key, err := readKey(filepath.Join(s.dir, entry.Name()))
+		if errors.Is(err, os.ErrNotExist) {
+			// 其它实例在列出目录之后删除了这个密钥
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("load key %s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b *Key) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
+
+// SetActivation 设置新密钥的激活延迟. 下游服务缓存 JWKS，其它实例定期重新加载密钥目录，
+// 新密钥需要在它们都获得公钥之后才能用来签发 Token，d 应该不小于 JWKS 的缓存时间加上重新加载的间隔.
+func (s *KeySet) SetActivation(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.activation = d
+}
+
+// Rotate 使用 algorithm 算法生成一个新的密钥，新密钥立即通过 JWKS 公开，经过激活延迟之后才用来签发 Token.
+func (s *KeySet) Rotate(algorithm string) (*Key, error) {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch algorithm {
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	// kid 以时间开头，方便按照文件名查看密钥的生成顺序
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	kid := time.Now().UTC().Format(kidTimeFormat) + "-" + fmt.Sprintf("%x", suffix)
+
+	// 先写入临时文件再重命名，其它实例不会读到写了一半的密钥
+	tmp, err := os.CreateTemp(s.dir, ".key-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := pem.Encode(tmp, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, kid+keyExt)); err != nil {
+		return nil, err
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s.Get(kid), nil
+}
+
+// Prune 删除被新密钥替换超过 retention 的旧密钥，retention 应该大于 Token 的有效期，
+// 保证用旧密钥签发的 Token 在过期之前仍然可以验证. 返回删除的密钥数.
+func (s *KeySet) Prune(retention time.Duration) (int, error) {
+	s.mu.RLock()
+	keys, activation := s.keys, s.activation
+	s.mu.RUnlock()
+
+	pruned := 0
+	for i := 0; i+1 < len(keys); i++ {
+		// keys[i] 从 keys[i+1] 激活时开始不再用来签发 Token
+		if time.Since(keys[i+1].CreatedAt) < activation+retention {
+			break
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, keys[i].ID+keyExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return pruned, err
+		}
+		pruned++
+	}
+
+	if pruned == 0 {
+		return 0, nil
+	}
+
+	return pruned, s.Reload()
+}
+
+// Current 返回用来签发 Token 的密钥，即生成超过激活延迟的最新密钥. 所有密钥都没有激活时（例如第一次生成密钥）
+// 返回最旧的密钥，没有密钥时返回 nil.
+func (s *KeySet) Current() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.keys) == 0 {
+		return nil
+	}
+
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if time.Since(s.keys[i].CreatedAt) >= s.activation {
+			return s.keys[i]
+		}
+	}
+
+	return s.keys[0]
+}
+
+// Latest 返回最新生成的密钥，包括还没有激活的密钥，没有密钥时返回 nil.
+func (s *KeySet) Latest() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.keys) == 0 {
+		return nil
+	}
+
+	return s.keys[len(s.keys)-1]
+}
+
+// Get 返回 kid 对应的密钥，不存在时返回 nil.
+func (s *KeySet) Get(kid string) *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+
+	return nil
+}
+
+// lookup 返回 kid 对应的密钥. kid 不存在时重新加载密钥目录后再查找一次，使其它实例刚生成的密钥可以立即用来验证，
+// 重新加载的间隔不小于 missReloadInterval.
+func (s *KeySet) lookup(kid string) *Key {
+	if key := s.Get(kid); key != nil || kid == "" {
+		return key
+	}
+
+	s.missMu.Lock()
+	if time.Since(s.lastMiss) < missReloadInterval {
+		s.missMu.Unlock()
+		return nil
+	}
+	s.lastMiss = time.Now()
+	s.missMu.Unlock()
+
+	if err := s.Reload(); err != nil {
+		return nil
+	}
+
+	return s.Get(kid)
+}
+
+// JWK 是 RFC 7517 定义的 JSON Web Key，只包含公钥.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// N 和 E 是 RSA 公钥的模数和指数.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve 和 X 是 Ed25519 公钥.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS 是 RFC 7517 定义的 JWK Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回所有密钥的公钥，下游服务可以用来离线验证 Token.
+func (s *KeySet) JWKS() *JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := &JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk := JWK{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// readKey 读取 PEM 格式的私钥文件，支持 PKCS #8 编码的 RSA 和 Ed25519 私钥以及 PKCS #1 编码的 RSA 私钥.
+func readKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: strings.TrimSuffix(filepath.Base(path), keyExt)}
+	if key.CreatedAt, err = keyTime(key.ID, path); err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Private = AlgorithmRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Private = AlgorithmEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", parsed)
+	}
+
+	return key, nil
+}
+
+// keyTime 返回密钥的生成时间. 文件的修改时间会在复制或者同步密钥目录时改变，所以优先使用 kid 开头记录的生成时间，
+// 手动放入目录、不符合命名规则的密钥使用文件的修改时间.
+func keyTime(kid, path string) (time.Time, error) {
+	prefix, _, _ := strings.Cut(kid, "-")
+	if t, err := time.Parse(kidTimeFormat, prefix); err == nil {
+		return t, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
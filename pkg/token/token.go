@@ -8,42 +8,97 @@ package token
 import (
 	"errors"
 	"fmt"
-	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 	"sync"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-// Config 包括token包的配置选项
-type Config struct {
-	key         string
-	identityKey string
+// Options 包括token包的配置选项
+type Options struct {
+	// Secret 是HS256签名的密钥，配置了非对称密钥时不使用
+	Secret string
+	// Issuer 写入token的iss，不为空时解析token会校验iss
+	Issuer string
+	// Audience 写入token的aud，不为空时解析token会校验aud中至少包含其中一个
+	Audience []string
+	// Expiry 是token的有效期
+	Expiry time.Duration
+	// Leeway 是校验exp、nbf和iat时允许的时钟偏差
+	Leeway time.Duration
 }
 
-// ErrMissingHeader 表示`Authorization`请求头为空
-var ErrMissingHeader = errors.New("the length of the `Authorization` header is zero")
+// config 是token包的配置
+type config struct {
+	Options
+	// keys 不为 nil 时使用非对称密钥签发和验证 token，否则使用 Secret 进行 HS256 签名
+	keys *KeySet
+}
 
 var (
-	config = Config{"Rtg8BPKNEf2mB4mgvKONGPZZQSaJWNLijxR42qRgq0iBb5", "identityKey"}
-	once   sync.Once
+	// ErrMissingHeader 表示`Authorization`请求头为空
+	ErrMissingHeader = errors.New("the length of the `Authorization` header is zero")
+
+	// ErrNoSigningKey 表示密钥目录中没有可以用来签发token的密钥
+	ErrNoSigningKey = errors.New("no signing key available")
+
+	// ErrUnknownKey 表示token头部的kid在密钥目录中不存在
+	ErrUnknownKey = errors.New("unknown signing key")
 )
 
-// Init 设置包级别的配置config,config会用于本包后面的token签发和解析
-func Init(key string, identityKey string) {
+var (
+	cfg  = config{Options: Options{Secret: "Rtg8BPKNEf2mB4mgvKONGPZZQSaJWNLijxR42qRgq0iBb5", Expiry: 30 * time.Minute}}
+	once sync.Once
+)
+
+// Init 设置包级别的配置,配置会用于本包后面的token签发和解析，零值字段使用默认配置
+func Init(opts *Options) {
 	once.Do(func() {
-		if key != "" {
-			config.key = key
+		if opts.Secret != "" {
+			cfg.Secret = opts.Secret
 		}
-		if identityKey != "" {
-			config.identityKey = identityKey
+		if opts.Expiry > 0 {
+			cfg.Expiry = opts.Expiry
 		}
+		cfg.Issuer, cfg.Audience, cfg.Leeway = opts.Issuer, opts.Audience, opts.Leeway
 	})
 }
 
-// Parse 使用指定的密钥key解析token,解析成功返回token上下文，否则报错
-func Parse(tokenString string, key string) (string, error) {
-	//	解析token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+// UseKeySet 使用非对称密钥签发和验证token，设置之后不再接受HS256签名的token
+func UseKeySet(keys *KeySet) {
+	cfg.keys = keys
+}
+
+// PublicKeys 返回用来验证token的公钥，使用HS256签名时返回空的JWK Set
+func PublicKeys() *JWKS {
+	if cfg.keys == nil {
+		return &JWKS{Keys: []JWK{}}
+	}
+
+	return cfg.keys.JWKS()
+}
+
+// Parse 使用指定的密钥key解析token,解析成功返回token中的claims，否则报错
+// 配置了非对称密钥时忽略key，根据token头部的kid查找验证所用的公钥，kid不存在时重新加载密钥目录
+func Parse(tokenString string, key string) (*Claims, error) {
+	claims := &Claims{}
+	//	解析token，claims的有效期、签发者和受众由Claims.Valid校验
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if cfg.keys != nil {
+			kid, _ := token.Header["kid"].(string)
+			k := cfg.keys.lookup(kid)
+			if k == nil {
+				return nil, ErrUnknownKey
+			}
+			//	确保token加密算法与密钥的算法一致，防止算法替换攻击
+			if token.Method.Alg() != k.method().Alg() {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return k.Private.Public(), nil
+		}
+
 		//	确保token加密算法是预期的加密算法
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
@@ -52,19 +107,27 @@ func Parse(tokenString string, key string) (string, error) {
 	})
 	//	解析失败
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	var identityKey string
-	//	如果解析成功，从token中取出token的主题
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		identityKey = claims[config.identityKey].(string)
+	if !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
 	}
-	return identityKey, err
+
+	return claims, nil
 }
 
 // ParseRequest 从请求头中获取令牌，并将其传递给Parse函数以解析令牌
-func ParseRequest(c *gin.Context) (string, error) {
+func ParseRequest(c *gin.Context) (*Claims, error) {
+	t, err := FromRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(t, cfg.Secret)
+}
+
+// FromRequest 从请求头中取出Bearer令牌
+func FromRequest(c *gin.Context) (string, error) {
 	header := c.Request.Header.Get("Authorization")
 	if len(header) == 0 {
 		return "", ErrMissingHeader
@@ -72,20 +135,52 @@ func ParseRequest(c *gin.Context) (string, error) {
 	var t string
 	// 从请求头中取出token
 	fmt.Sscanf(header, "Bearer %s", &t)
-	return Parse(t, config.key)
+
+	return t, nil
 }
 
-// Sign 使用jwtSecret签发token,token的claims中会存放传入的subject
-func Sign(identityKey string) (tokenString string, err error) {
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256, jwt.MapClaims{
-			config.identityKey: identityKey,
-			"nbf":              time.Now().Unix(),
-			"iat":              time.Now().Unix(),
-			"exp":              time.Now().Add(time.Minute * 30).Unix(),
-		})
+// Sign 签发token，claims中为空的iss、aud、jti、iat、nbf和exp使用配置生成
+// 配置了非对称密钥时使用已经激活的最新密钥签名并在头部写入kid，否则使用Secret进行HS256签名
+func Sign(claims *Claims) (tokenString string, err error) {
+	var (
+		method  jwt.SigningMethod = jwt.SigningMethodHS256
+		signKey interface{}       = []byte(cfg.Secret)
+		kid     string
+	)
+	if cfg.keys != nil {
+		k := cfg.keys.Current()
+		if k == nil {
+			return "", ErrNoSigningKey
+		}
+		method, signKey, kid = k.method(), k.Private, k.ID
+	}
+
+	now := time.Now()
+	if claims.Issuer == "" {
+		claims.Issuer = cfg.Issuer
+	}
+	if len(claims.Audience) == 0 {
+		claims.Audience = cfg.Audience
+	}
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if claims.NotBefore == nil {
+		claims.NotBefore = jwt.NewNumericDate(now)
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(cfg.Expiry))
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
 
 	// 签发token
-	tokenString, err = token.SignedString([]byte(config.key))
+	tokenString, err = token.SignedString(signKey)
 	return
 }
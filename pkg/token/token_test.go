@@ -0,0 +1,307 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "miniblog-test-secret"
+
+func TestParseClaims(t *testing.T) {
+	useConfig(t, config{Options: Options{
+		Secret:   testSecret,
+		Issuer:   "miniblog",
+		Audience: []string{"miniblog-api", "miniblog-web"},
+		Expiry:   time.Hour,
+		Leeway:   time.Minute,
+	}})
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		claims  jwt.RegisteredClaims
+		wantErr error
+	}{
+		{name: "valid", claims: registered(now)},
+		{name: "second audience", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"miniblog-web"} })},
+		{name: "expired within leeway", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-30 * time.Second)) })},
+		{name: "expired", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-2 * time.Minute)) }), wantErr: jwt.ErrTokenExpired},
+		{name: "missing exp", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil }), wantErr: jwt.ErrTokenExpired},
+		{name: "not valid yet", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.NotBefore = jwt.NewNumericDate(now.Add(2 * time.Minute)) }), wantErr: jwt.ErrTokenNotValidYet},
+		{name: "issued in the future", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.IssuedAt = jwt.NewNumericDate(now.Add(2 * time.Minute)) }), wantErr: jwt.ErrTokenUsedBeforeIssued},
+		{name: "wrong issuer", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.Issuer = "evil" }), wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "wrong audience", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"other-api"} }), wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "missing audience", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.Audience = nil }), wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "missing subject", claims: with(registered(now), func(c *jwt.RegisteredClaims) { c.Subject = "" }), wantErr: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := signWith(t, jwt.SigningMethodHS256, []byte(testSecret), "", &Claims{RegisteredClaims: tt.claims})
+
+			claims, err := Parse(s, testSecret)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Parse() returned error: %v", err)
+				}
+				if claims.Subject != tt.claims.Subject {
+					t.Errorf("Parse() subject = %q, want %q", claims.Subject, tt.claims.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHS256(t *testing.T) {
+	useConfig(t, config{Options: Options{Secret: testSecret, Expiry: time.Hour}})
+
+	s, err := Sign(NewClaims("alice", []string{"admin"}, []string{"posts:read"}))
+	if err != nil {
+		t.Fatalf("Sign() returned error: %v", err)
+	}
+
+	claims, err := Parse(s, testSecret)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if claims.Subject != "alice" || !claims.HasRole("admin") || !claims.HasScope("posts:read") {
+		t.Errorf("Parse() = %+v, want the signed claims", claims)
+	}
+
+	if _, err := Parse(s, "wrong-secret"); !errors.Is(err, jwt.ErrSignatureInvalid) {
+		t.Errorf("Parse() with wrong secret error = %v, want %v", err, jwt.ErrSignatureInvalid)
+	}
+
+	// 没有配置非对称密钥时只接受 HS256 签名的 token
+	keys := newKeySet(t)
+	key := rotate(t, keys, AlgorithmRS256)
+	s = signWith(t, jwt.SigningMethodRS256, key.Private, key.ID, &Claims{RegisteredClaims: registered(time.Now())})
+	if _, err := Parse(s, testSecret); !errors.Is(err, jwt.ErrSignatureInvalid) {
+		t.Errorf("Parse() of RS256 token error = %v, want %v", err, jwt.ErrSignatureInvalid)
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	keys := newKeySet(t)
+	rsaKey := rotate(t, keys, AlgorithmRS256)
+	edKey := rotate(t, keys, AlgorithmEdDSA)
+	useConfig(t, config{Options: Options{Secret: testSecret, Expiry: time.Hour}, keys: keys})
+
+	rsaPublic, err := x509.MarshalPKIXPublicKey(rsaKey.Private.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() returned error: %v", err)
+	}
+
+	claims := func() *Claims { return &Claims{RegisteredClaims: registered(time.Now())} }
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "RS256", token: signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims())},
+		{name: "EdDSA", token: signWith(t, jwt.SigningMethodEdDSA, edKey.Private, edKey.ID, claims())},
+		{name: "missing kid", token: signWith(t, jwt.SigningMethodRS256, rsaKey.Private, "", claims()), wantErr: ErrUnknownKey},
+		{name: "unknown kid", token: signWith(t, jwt.SigningMethodRS256, rsaKey.Private, "20240101T000000Z-deadbeef", claims()), wantErr: ErrUnknownKey},
+		{name: "kid of another key", token: signWith(t, jwt.SigningMethodEdDSA, edKey.Private, rsaKey.ID, claims()), wantErr: jwt.ErrSignatureInvalid},
+		// 使用公钥作为 HMAC 密钥伪造 token 的算法替换攻击
+		{name: "HS256 with public key", token: signWith(t, jwt.SigningMethodHS256, rsaPublic, rsaKey.ID, claims()), wantErr: jwt.ErrSignatureInvalid},
+		{name: "HS256 with secret", token: signWith(t, jwt.SigningMethodHS256, []byte(testSecret), rsaKey.ID, claims()), wantErr: jwt.ErrSignatureInvalid},
+		{name: "HS256 without kid", token: signWith(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims()), wantErr: ErrUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.token, testSecret)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Parse() returned error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Sign 使用最新的密钥签名，签发的 token 可以被解析
+	s, err := Sign(NewClaims("alice", nil, nil))
+	if err != nil {
+		t.Fatalf("Sign() returned error: %v", err)
+	}
+	if _, err := Parse(s, ""); err != nil {
+		t.Errorf("Parse() of signed token returned error: %v", err)
+	}
+}
+
+func TestParseReloadsUnknownKey(t *testing.T) {
+	keys := newKeySet(t)
+	rotate(t, keys, AlgorithmEdDSA)
+	useConfig(t, config{Options: Options{Secret: testSecret, Expiry: time.Hour}, keys: keys})
+
+	// 共享密钥目录的另一个实例生成的密钥
+	other, err := LoadKeySet(keys.dir)
+	if err != nil {
+		t.Fatalf("LoadKeySet() returned error: %v", err)
+	}
+	claims := func() *Claims { return &Claims{RegisteredClaims: registered(time.Now())} }
+
+	key := rotate(t, other, AlgorithmEdDSA)
+	if _, err := Parse(signWith(t, jwt.SigningMethodEdDSA, key.Private, key.ID, claims()), ""); err != nil {
+		t.Errorf("Parse() of token signed by a newly loaded key returned error: %v", err)
+	}
+
+	// 刚刚重新加载过时不再重新加载
+	key = rotate(t, other, AlgorithmEdDSA)
+	if _, err := Parse(signWith(t, jwt.SigningMethodEdDSA, key.Private, key.ID, claims()), ""); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestKeySetActivation(t *testing.T) {
+	keys := newKeySet(t)
+	keys.SetActivation(time.Hour)
+	now := time.Now()
+
+	// 所有密钥都没有激活时使用最旧的密钥
+	first := writeKey(t, keys, now.Add(-20*time.Minute))
+	second := writeKey(t, keys, now.Add(-10*time.Minute))
+	if got := keys.Current().ID; got != first {
+		t.Errorf("Current() = %s, want %s", got, first)
+	}
+	if got := keys.Latest().ID; got != second {
+		t.Errorf("Latest() = %s, want %s", got, second)
+	}
+
+	// 新密钥激活之前继续使用已经激活的最新密钥
+	keys = newKeySet(t)
+	keys.SetActivation(time.Hour)
+	first = writeKey(t, keys, now.Add(-5*time.Hour))
+	second = writeKey(t, keys, now.Add(-90*time.Minute))
+	third := writeKey(t, keys, now.Add(-5*time.Minute))
+	if got := keys.Current().ID; got != second {
+		t.Errorf("Current() = %s, want %s", got, second)
+	}
+
+	// second 在 30 分钟前激活，first 从那时开始计算保留时间
+	if n, err := keys.Prune(40 * time.Minute); err != nil || n != 0 {
+		t.Errorf("Prune(40m) = %d, %v, want 0, nil", n, err)
+	}
+	if n, err := keys.Prune(20 * time.Minute); err != nil || n != 1 {
+		t.Errorf("Prune(20m) = %d, %v, want 1, nil", n, err)
+	}
+	if keys.Get(first) != nil || keys.Get(second) == nil || keys.Get(third) == nil {
+		t.Errorf("Prune(20m) should delete only %s", first)
+	}
+}
+
+// useConfig 在测试期间使用 c 作为包级别的配置，测试结束后恢复.
+func useConfig(t *testing.T, c config) {
+	t.Helper()
+
+	saved := cfg
+	cfg = c
+	t.Cleanup(func() { cfg = saved })
+}
+
+// registered 返回 now 时刻签发、符合 TestParseClaims 配置的 registered claims.
+func registered(now time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    "miniblog",
+		Subject:   "alice",
+		Audience:  jwt.ClaimStrings{"miniblog-api"},
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+// with 返回经过 modify 修改的 claims.
+func with(c jwt.RegisteredClaims, modify func(*jwt.RegisteredClaims)) jwt.RegisteredClaims {
+	modify(&c)
+	return c
+}
+
+// signWith 使用指定的算法和密钥签名 claims，kid 不为空时写入 token 头部.
+func signWith(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims *Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() returned error: %v", err)
+	}
+
+	return s
+}
+
+// newKeySet 在临时目录中创建一个空的 KeySet.
+func newKeySet(t *testing.T) *KeySet {
+	t.Helper()
+
+	keys, err := LoadKeySet(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadKeySet() returned error: %v", err)
+	}
+
+	return keys
+}
+
+// writeKey 在 keys 的目录中写入一个 created 时生成的 Ed25519 密钥，重新加载并返回 kid.
+func writeKey(t *testing.T, keys *KeySet, created time.Time) string {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() returned error: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() returned error: %v", err)
+	}
+
+	kid := created.UTC().Format(kidTimeFormat) + "-test"
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(keys.dir, kid+keyExt), data, 0o600); err != nil {
+		t.Fatalf("WriteFile() returned error: %v", err)
+	}
+	if err := keys.Reload(); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	return kid
+}
+
+// rotate 在 keys 中生成一个 algorithm 算法的密钥.
+func rotate(t *testing.T, keys *KeySet, algorithm string) *Key {
+	t.Helper()
+
+	key, err := keys.Rotate(algorithm)
+	if err != nil {
+		t.Fatalf("Rotate(%s) returned error: %v", algorithm, err)
+	}
+
+	return key
+}
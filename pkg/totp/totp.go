@@ -0,0 +1,128 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+// Package totp 实现了 RFC 6238 定义的基于时间的一次性密码（TOTP），
+// 使用 HMAC-SHA1、6 位数字和 30 秒的时间步长，与常见的身份验证器应用兼容.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits 是一次性密码的位数.
+	Digits = 6
+	// Period 是时间步长，每个时间步长生成一个新的一次性密码.
+	Period = 30 * time.Second
+
+	// secretSize 是密钥的字节数，RFC 4226 推荐使用 160 位密钥.
+	secretSize = 20
+)
+
+// ErrInvalidSecret 表示密钥不是合法的 base32 编码.
+var ErrInvalidSecret = errors.New("totp: invalid secret")
+
+// encoding 是密钥使用的 base32 编码，身份验证器应用通常不接受填充字符.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret 生成一个随机密钥，返回 base32 编码的结果.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(b), nil
+}
+
+// Counter 返回 t 所在的时间步长.
+func Counter(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Generate 返回密钥 secret 在 t 时刻的一次性密码.
+func Generate(secret string, t time.Time) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, Counter(t)), nil
+}
+
+// Validate 检查 code 是否是密钥 secret 在 t 时刻前后 skew 个时间步长内的一次性密码，
+// 返回匹配的时间步长. 调用方应该拒绝不大于上一次验证成功的时间步长，防止同一个密码被重复使用.
+func Validate(secret, code string, t time.Time, skew int) (int64, bool) {
+	key, err := decode(secret)
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	counter := Counter(t)
+	for i := -skew; i <= skew; i++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, counter+int64(i))), []byte(code)) == 1 {
+			return counter + int64(i), true
+		}
+	}
+
+	return 0, false
+}
+
+// URI 返回身份验证器应用可以导入的 otpauth URI，通常以二维码的形式展示给用户.
+func URI(issuer, account, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+
+	return u.String()
+}
+
+// hotp 返回 RFC 4226 定义的基于计数器的一次性密码.
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// 动态截断，取摘要中由最后 4 位指定位置开始的 31 位
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%uint32(math.Pow10(Digits)))
+}
+
+// decode 解码 base32 编码的密钥，忽略大小写、空格和填充字符.
+func decode(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := encoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+
+	return key, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret 是 RFC 4226 和 RFC 6238 测试向量使用的 SHA-1 密钥 "12345678901234567890" 的 base32 编码.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestHOTP 使用 RFC 4226 附录 D 的测试向量.
+func TestHOTP(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	key := []byte("12345678901234567890")
+	for counter, code := range want {
+		if got := hotp(key, int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// TestGenerate 使用 RFC 6238 附录 B 中 SHA-1 的测试向量，RFC 使用 8 位数字，这里取最后 6 位.
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Generate(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Generate(%d) returned error: %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("Generate(%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	counter := Counter(now)
+
+	tests := []struct {
+		name        string
+		secret      string
+		code        string
+		skew        int
+		wantCounter int64
+		wantOK      bool
+	}{
+		{name: "current step", secret: rfcSecret, code: "005924", skew: 1, wantCounter: counter, wantOK: true},
+		{name: "previous step within skew", secret: rfcSecret, code: mustGenerate(t, now.Add(-Period)), skew: 1, wantCounter: counter - 1, wantOK: true},
+		{name: "next step within skew", secret: rfcSecret, code: mustGenerate(t, now.Add(Period)), skew: 1, wantCounter: counter + 1, wantOK: true},
+		{name: "outside skew", secret: rfcSecret, code: mustGenerate(t, now.Add(-2*Period)), skew: 1},
+		{name: "no skew", secret: rfcSecret, code: mustGenerate(t, now.Add(Period)), skew: 0},
+		{name: "wrong code", secret: rfcSecret, code: "000000", skew: 1},
+		{name: "too short", secret: rfcSecret, code: "05924", skew: 1},
+		{name: "too long", secret: rfcSecret, code: "89005924", skew: 1},
+		{name: "lower case secret with spaces", secret: "gezd gnbv gy3t qojq gezd gnbv gy3t qojq", code: "005924", skew: 1, wantCounter: counter, wantOK: true},
+		{name: "invalid secret", secret: "not base32!", code: "005924", skew: 1},
+		{name: "empty secret", secret: "", code: "005924", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCounter, gotOK := Validate(tt.secret, tt.code, now, tt.skew)
+			if gotOK != tt.wantOK || gotCounter != tt.wantCounter {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", gotCounter, gotOK, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() returned error: %v", err)
+	}
+
+	key, err := decode(secret)
+	if err != nil {
+		t.Fatalf("decode(%q) returned error: %v", secret, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("len(key) = %d, want %d", len(key), secretSize)
+	}
+
+	now := time.Now()
+	code, err := Generate(secret, now)
+	if err != nil {
+		t.Fatalf("Generate() returned error: %v", err)
+	}
+	if _, ok := Validate(secret, code, now, 0); !ok {
+		t.Errorf("Validate() rejected the code generated from the same secret")
+	}
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("miniblog", "alice", rfcSecret))
+	if err != nil {
+		t.Fatalf("url.Parse() returned error: %v", err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/miniblog:alice" {
+		t.Errorf("URI() = %s, want otpauth://totp/miniblog:alice", u)
+	}
+
+	q := u.Query()
+	for key, want := range map[string]string{"secret": rfcSecret, "issuer": "miniblog", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := q.Get(key); got != want {
+			t.Errorf("query %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// mustGenerate 返回 RFC 测试密钥在 t 时刻的一次性密码.
+func mustGenerate(t *testing.T, at time.Time) string {
+	t.Helper()
+
+	code, err := Generate(rfcSecret, at)
+	if err != nil {
+		t.Fatalf("Generate() returned error: %v", err)
+	}
+
+	return code
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package id
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// alphabet 是短 ID 使用的字符集，只包含小写字母和数字，方便在 URL 中使用.
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// shortIDLength 是 GenShortID 生成的 ID 长度.
+const shortIDLength = 10
+
+// GenShortID 生成 10 位字符长度的随机唯一 ID.
+func GenShortID() string {
+	max := big.NewInt(int64(len(alphabet)))
+	b := make([]byte, shortIDLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 Innkeeper cheng <wangcheng.public@gmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://github.com/ischeng28/miniblog.
+
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// folder 用来对字符串做大小写折叠，比 strings.ToLower 能处理更多语言的大小写变体.
+var folder = cases.Fold()
+
+// Make 将 s 转换为可以在 URL 中使用的 slug.
+// 转换时先做 NFKC 规范化和大小写折叠，然后保留字母和数字，其余连续的字符替换为一个 `-`.
+// 例如 "Go  Modules" 和 "go-modules" 会得到相同的 slug "go-modules".
+func Make(s string) string {
+	s = folder.String(norm.NFKC.String(s))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+
+			continue
+		}
+		dash = true
+	}
+
+	return b.String()
+}